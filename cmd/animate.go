@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/automaton"
+	"automata-compiler/pkg/diagram"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// animateCmd runs the automaton and writes its run as sequence of diagrams, one per step
+var animateCmd = &cobra.Command{
+	Use:   "animate [AUTOMATON_TYPE] PATH_TO_INPUT_FILE",
+	Short: "Export run of the automaton as animated state diagram",
+	Long: `Compiles and runs the automaton, then writes state diagram for every step of the calculations,
+with the current state and the last taken transition highlighted, together with the current input, stack or tape.
+Supported formats:
+- html (self-contained HTML page with step controls)
+- dot (directory with one Graphviz DOT file per step)`,
+	RunE:         runAnimateCmd,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+}
+
+func init() {
+	animateCmd.Flags().Uint32P(timeoutFlag.name, timeoutFlag.short, 3000, "Timeout in miliseconds after which program will stop any remaining calculations. Set this value to 0 if you don't want any timeout.")
+	animateCmd.Flags().Uint32(maxStepsFlag.name, 0, "Maximum number of steps after which program will stop any remaining calculations. Set this value to 0 if you don't want any step limit.")
+	animateCmd.Flags().StringP(formatFlag.name, formatFlag.short, "html", "Animation format, either 'html' or 'dot'.")
+	animateCmd.Flags().StringP(output.name, output.short, "", "Use this flag to specify filepath where HTML page should be placed (`stdout` is used if empty) or directory for DOT frames (required).")
+	addInputFlags(animateCmd)
+	rootCmd.AddCommand(animateCmd)
+}
+
+func runAnimateCmd(cmd *cobra.Command, args []string) error {
+	aType, source, err := sourceArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag.name)
+	if err != nil {
+		return err
+	}
+	format = strings.ToLower(format)
+	if format != "html" && format != "dot" {
+		return fmt.Errorf("unsupported animation format: '%s'", format)
+	}
+	outputPath, err := cmd.Flags().GetString(output.name)
+	if err != nil {
+		return err
+	}
+	if format == "dot" && outputPath == "" {
+		return errors.New("output directory must be provided for 'dot' format")
+	}
+	timeout, err := cmd.Flags().GetUint32(timeoutFlag.name)
+	if err != nil {
+		return err
+	}
+	maxSteps, err := cmd.Flags().GetUint32(maxStepsFlag.name)
+	if err != nil {
+		return err
+	}
+	input, err := externalInput(cmd)
+	if err != nil {
+		return err
+	}
+	a, err := compileWithInput(aType, source, input)
+	if err != nil {
+		return err
+	}
+	g, err := diagram.NewGraph(a)
+	if err != nil {
+		return err
+	}
+	trace := make([]automaton.AutomatonCurrentCalculationsState, 0)
+	opts := automaton.AutomatonOptions{
+		MaxSteps: int(maxSteps),
+		Trace: func(cs automaton.AutomatonCurrentCalculationsState) {
+			trace = append(trace, cs)
+		},
+	}
+	ctx, cancelFunc := createContextWithTimeout(timeout)
+	defer cancelFunc()
+	// Run is allowed to fail, steps made until the failure are still animated
+	_, runErr := automaton.Run(ctx, a, opts)
+	frames, err := diagram.NewFrames(trace)
+	if err != nil {
+		return err
+	}
+	if format == "dot" {
+		err = writeDOTFrames(g, frames, outputPath)
+	} else {
+		err = writeHTMLAnimation(g, frames, outputPath)
+	}
+	if err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("error during running stage: %w", runErr)
+	}
+	return nil
+}
+
+func writeHTMLAnimation(g diagram.Graph, frames []diagram.Frame, outputPath string) error {
+	w, cleanupFunc, err := openOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer cleanupFunc()
+	return g.WriteHTMLAnimation(w, frames)
+}
+
+// writeDOTFrames writes every frame to separate file `frame_<step>.dot` in `dir`
+func writeDOTFrames(g diagram.Graph, frames []diagram.Frame, dir string) error {
+	for i, f := range frames {
+		w, cleanupFunc, err := openOutput(filepath.Join(dir, fmt.Sprintf("frame_%05d.dot", i)))
+		if err != nil {
+			return err
+		}
+		err = g.WriteDOTFrame(w, f)
+		cleanupFunc()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
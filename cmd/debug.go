@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/automaton"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// debugCmd runs the automaton step by step, controlled by commands read from stdin
+var debugCmd = &cobra.Command{
+	Use:   "debug [AUTOMATON_TYPE] PATH_TO_INPUT_FILE",
+	Short: "Run the automaton in the interactive step debugger",
+	Long: `Compiles the automaton and runs it in the interactive debugger, which reads commands from stdin.
+Type 'help' to list available commands.`,
+	RunE:         runDebugCmd,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+}
+
+const debugHelp = `Commands:
+  step [N], s [N]            make N moves (1 by default)
+  back [N], r [N]            undo N moves (1 by default)
+  goto N, g N                go backward or forward to the configuration after N moves
+  continue, c                run until a breakpoint is hit or calculations finish (Ctrl+C interrupts)
+  break state NAME, b ...    stop when automaton enters state NAME
+  break symbol NAME          stop when symbol NAME is under the head (next input symbol for DFA and PA)
+  break step N               stop after N moves
+  breakpoints, bl            list breakpoints
+  delete N, d N              delete breakpoint N
+  print, p                   print the current configuration
+  when head N                print the first step in which the head was at cell N (position in the input for DFA and PA)
+  when state NAME            print the last step in which automaton entered state NAME
+  help, h                    print this help
+  quit, q                    quit the debugger
+`
+
+func init() {
+	addInputFlags(debugCmd)
+	rootCmd.AddCommand(debugCmd)
+}
+
+func runDebugCmd(cmd *cobra.Command, args []string) error {
+	if sourcePath(args) == "-" {
+		return errors.New("stdin is used for debugger commands, the source must be read from a file")
+	}
+	aType, source, err := sourceArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	input, err := externalInput(cmd)
+	if err != nil {
+		return err
+	}
+	a, err := compileWithInput(aType, source, input)
+	if err != nil {
+		return err
+	}
+	s := debugSession{d: automaton.NewDebugger(a), out: cmd.OutOrStdout()}
+	return s.run(cmd.InOrStdin())
+}
+
+// debugSession executes debugger commands and writes their results to `out`
+type debugSession struct {
+	d   *automaton.Debugger
+	out io.Writer
+}
+
+func (s debugSession) run(in io.Reader) error {
+	s.printState()
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, "(debug) ")
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			return nil
+		}
+		if err := s.execute(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(s.out, "error: %s\n", err.Error())
+		}
+	}
+}
+
+func (s debugSession) execute(command string, args []string) error {
+	switch command {
+	case "step", "s":
+		return s.step(args)
+	case "back", "r":
+		return s.back(args)
+	case "goto", "g":
+		if len(args) != 1 {
+			return errors.New("usage: goto N")
+		}
+		step, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid step number: %s", args[0])
+		}
+		err = s.d.GoTo(step)
+		s.printState()
+		return err
+	case "continue", "c":
+		return s.continueToBreakpoint()
+	case "break", "b":
+		return s.addBreakpoint(args)
+	case "breakpoints", "bl":
+		for i, b := range s.d.Breakpoints() {
+			fmt.Fprintf(s.out, "%d: %s\n", i, b)
+		}
+		return nil
+	case "delete", "d":
+		if len(args) != 1 {
+			return errors.New("usage: delete N")
+		}
+		i, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid breakpoint number: %s", args[0])
+		}
+		return s.d.RemoveBreakpoint(i)
+	case "print", "p":
+		s.printState()
+		return nil
+	case "when":
+		return s.when(args)
+	case "help", "h":
+		fmt.Fprint(s.out, debugHelp)
+		return nil
+	default:
+		return fmt.Errorf("unknown command: %s, type 'help' to list available commands", command)
+	}
+}
+
+func (s debugSession) step(args []string) error {
+	n, err := stepsArg(args)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := s.d.Step(); err != nil {
+			return err
+		}
+		if s.d.Finished() {
+			break
+		}
+	}
+	s.printState()
+	return nil
+}
+
+func (s debugSession) back(args []string) error {
+	n, err := stepsArg(args)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := s.d.Back(); err != nil {
+			return err
+		}
+	}
+	s.printState()
+	return nil
+}
+
+func (s debugSession) when(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: when head N | when state NAME")
+	}
+	switch args[0] {
+	case "head":
+		cell, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid cell number: %s", args[1])
+		}
+		if step, ok := s.d.HeadFirstReached(cell); ok {
+			fmt.Fprintf(s.out, "head first reached cell %d in step %d\n", cell, step)
+		} else {
+			fmt.Fprintf(s.out, "head hasn't reached cell %d yet\n", cell)
+		}
+	case "state":
+		if step, ok := s.d.StateLastEntered(args[1]); ok {
+			fmt.Fprintf(s.out, "state %s last entered in step %d\n", args[1], step)
+		} else {
+			fmt.Fprintf(s.out, "state %s hasn't been entered yet\n", args[1])
+		}
+	default:
+		return fmt.Errorf("unknown query: %s", args[0])
+	}
+	return nil
+}
+
+func (s debugSession) continueToBreakpoint() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	b, err := s.d.Continue(ctx)
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(s.out, "interrupted")
+	} else if err != nil {
+		return err
+	}
+	if b != nil {
+		fmt.Fprintf(s.out, "breakpoint hit: %s\n", b)
+	}
+	s.printState()
+	return nil
+}
+
+func (s debugSession) addBreakpoint(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: break state|symbol|step VALUE")
+	}
+	switch args[0] {
+	case "state":
+		s.d.AddBreakpoint(automaton.StateBreakpoint{StateName: args[1]})
+	case "symbol":
+		s.d.AddBreakpoint(automaton.SymbolBreakpoint{SymbolName: args[1]})
+	case "step":
+		step, err := strconv.Atoi(args[1])
+		if err != nil || step < 1 {
+			return fmt.Errorf("invalid step number: %s", args[1])
+		}
+		s.d.AddBreakpoint(automaton.StepBreakpoint{Step: step})
+	default:
+		return fmt.Errorf("unknown breakpoint type: %s", args[0])
+	}
+	return nil
+}
+
+// stepsArg returns the number of steps given as the only optional argument, 1 if it's omitted
+func stepsArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of steps: %s", args[0])
+	}
+	return n, nil
+}
+
+// printState writes the current configuration, followed by the result if calculations finished
+func (s debugSession) printState() {
+	fmt.Fprintf(s.out, "step %d\n", s.d.Steps())
+	s.d.State().SaveState(s.out)
+	if result, err := s.d.Result(); err == nil {
+		fmt.Fprint(s.out, "calculations finished, ")
+		result.SaveResult(s.out)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/automaton"
+	"automata-compiler/pkg/compiler"
+	"automata-compiler/pkg/lexer"
+	"errors"
+)
+
+// Exit codes of the program, so it can be used in shell scripts and Makefiles
+const (
+	// ExitAccepted is returned when automaton accepts the input, or command succeeds
+	ExitAccepted = 0
+	// ExitFailure is returned for invalid arguments, I/O errors, failed test cases and reported lint warnings
+	ExitFailure = 1
+	// ExitRejected is returned when automaton finishes calculations, but rejects the input
+	ExitRejected = 2
+	// ExitCompileError is returned when the source or input can't be lexed or compiled
+	ExitCompileError = 3
+	// ExitRuntimeError is returned when calculations can't be continued, e.g. because of a missing transition
+	ExitRuntimeError = 4
+	// ExitNotFinished is returned when calculations are stopped by the timeout, step limit or an infinite loop
+	ExitNotFinished = 5
+)
+
+// errRejected is returned when automaton rejects the input, the result is already written so it's not printed
+var errRejected = errors.New("input rejected")
+
+// exitCode returns exit code of the program for the error returned by a command
+func exitCode(err error) int {
+	if err == nil {
+		return ExitAccepted
+	}
+	if errors.Is(err, errRejected) {
+		return ExitRejected
+	}
+	var (
+		lexErr       lexer.LexError
+		compileErr   compiler.CompileError
+		compileErrs  compiler.Errors
+		timeoutErr   automaton.TimeoutError
+		stepLimitErr automaton.StepLimitExceededError
+		loopErr      automaton.InfiniteLoopError
+		missingErr   automaton.MissingTransitionError
+		underflowErr automaton.TapeUnderflowError
+		emptyErr     automaton.EmptyStackError
+	)
+	switch {
+	case errors.As(err, &lexErr), errors.As(err, &compileErr), errors.As(err, &compileErrs):
+		return ExitCompileError
+	case errors.As(err, &timeoutErr), errors.As(err, &stepLimitErr), errors.As(err, &loopErr):
+		return ExitNotFinished
+	case errors.As(err, &missingErr), errors.As(err, &underflowErr), errors.As(err, &emptyErr):
+		return ExitRuntimeError
+	default:
+		return ExitFailure
+	}
+}
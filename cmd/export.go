@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/diagram"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// exportCmd writes diagram of the automaton defined in the source file
+var exportCmd = &cobra.Command{
+	Use:   "export [AUTOMATON_TYPE] PATH_TO_INPUT_FILE",
+	Short: "Export state diagram of the automaton",
+	Long: `Compiles the automaton and writes its state diagram in the selected format.
+Supported formats:
+- dot (Graphviz DOT language)
+- mermaid (Mermaid stateDiagram-v2)
+- plantuml (PlantUML state diagram)`,
+	RunE:         runExportCmd,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+}
+
+func init() {
+	exportCmd.Flags().StringP(formatFlag.name, formatFlag.short, "dot", "Diagram format, one of 'dot', 'mermaid' or 'plantuml'.")
+	exportCmd.Flags().StringP(output.name, output.short, "", "Use this flag to specify filepath where diagram should be placed. If you want to use `stdout` leave this option empty.")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportCmd(cmd *cobra.Command, args []string) error {
+	aType, source, err := sourceArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag.name)
+	if err != nil {
+		return err
+	}
+	writeDiagram, err := diagramWriter(format)
+	if err != nil {
+		return err
+	}
+	outputPath, err := cmd.Flags().GetString(output.name)
+	if err != nil {
+		return err
+	}
+	_, a, err := compileSource(aType, source)
+	if err != nil {
+		return err
+	}
+	g, err := diagram.NewGraph(a)
+	if err != nil {
+		return err
+	}
+	w, cleanupFunc, err := openOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer cleanupFunc()
+	return writeDiagram(g, w)
+}
+
+// diagramWriter returns function writing graph in the given format
+func diagramWriter(format string) (func(g diagram.Graph, w io.Writer) error, error) {
+	switch strings.ToLower(format) {
+	case "dot":
+		return diagram.Graph.WriteDOT, nil
+	case "mermaid":
+		return diagram.Graph.WriteMermaid, nil
+	case "plantuml":
+		return diagram.Graph.WritePlantUML, nil
+	default:
+		return nil, fmt.Errorf("unsupported diagram format: '%s'", format)
+	}
+}
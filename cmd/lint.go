@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/lint"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// lintCmd reports likely mistakes in the automaton defined in the source file
+var lintCmd = &cobra.Command{
+	Use:     "lint [AUTOMATON_TYPE] PATH_TO_INPUT_FILE",
+	Aliases: []string{"check"},
+	Short:   "Report likely mistakes in the automaton",
+	Long: `Compiles the automaton and reports:
+- states unreachable from the initial state
+- states from which no accepting state is reachable
+- symbols declared but never used in transitions
+- transitions from accepting states of TM, which are never used as calculations stop there
+The command exits with a non-zero code if any warning is reported.`,
+	RunE:         runLintCmd,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLintCmd(cmd *cobra.Command, args []string) error {
+	aType, source, err := sourceArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	c, a, err := compileSource(aType, source)
+	if err != nil {
+		return err
+	}
+	warnings, err := lint.Lint(a, c.SourceLines())
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	for _, w := range warnings {
+		fmt.Fprintf(out, "%s\n", w)
+	}
+	if len(warnings) == 1 {
+		return errors.New("1 warning found")
+	}
+	if len(warnings) > 1 {
+		return fmt.Errorf("%d warnings found", len(warnings))
+	}
+	fmt.Fprintln(out, "no warnings found")
+	return nil
+}
@@ -4,8 +4,12 @@ import (
 	"automata-compiler/pkg/automaton"
 	"automata-compiler/pkg/compiler"
 	"automata-compiler/pkg/lexer"
+	"automata-compiler/pkg/macro"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,25 +20,37 @@ import (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "automata-compiler AUTOMATON_TYPE PAHT_TO_INPUT_FILE",
+	Use:   "automata-compiler [AUTOMATON_TYPE] PAHT_TO_INPUT_FILE",
 	Short: "automata-compiler is a tool for simulating automata",
 	Long: `The automata-compiler is a CLI application for compiling and running automata code.
 It supports Deterministic Finite Automata, Pushdown Automaton and Turing Machines. 
 AUTOMATON_TYPE is one of the following
 - DFA (for Deterministic Finite Automaton)
 - PA (for Pushdown Automaton)
-- TM (for Turing Machine)`,
-	RunE: runRootCmd,
-	Args: cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs),
+- TM (for Turing Machine)
+If AUTOMATON_TYPE is omitted, it's taken from the header of the source (e.g. 'type TM;')
+or from the extension of the file (.dfa, .pa or .tm).
+Use '-' as PAHT_TO_INPUT_FILE to read the source from stdin.`,
+	RunE:         runRootCmd,
+	Args:         cobra.MatchAll(cobra.RangeArgs(1, 2), cobra.OnlyValidArgs),
+	SilenceUsage: true,
+	// Errors are printed by `Execute`
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+//
+// Errors are written to stderr and the program exits with one of the exit codes described in `exitCode`.
 func Execute() {
 	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, errRejected) {
+		fmt.Fprintln(os.Stderr, err.Error())
 	}
+	os.Exit(exitCode(err))
 }
 
 type flag struct {
@@ -46,25 +62,37 @@ var (
 	timeoutFlag         = flag{name: "timeout", short: "t"}
 	output              = flag{name: "output", short: "o"}
 	includeCalculations = flag{name: "include-calculations", short: "i"}
+	maxStepsFlag        = flag{name: "max-steps"}
+	statsFlag           = flag{name: "stats"}
+	formatFlag          = flag{name: "format", short: "f"}
+	inputFlag           = flag{name: "input"}
+	inputFileFlag       = flag{name: "input-file"}
 )
 
 func init() {
 	// Here you will define your flags and configuration settings.
 	rootCmd.Flags().Uint32P(timeoutFlag.name, timeoutFlag.short, 3000, "Timeout in miliseconds after which program will stop any remaining calculations. It's useful as many automata can enter infinite loop for some input values. Set this value to 0 if you don't want any timeout.")
+	rootCmd.Flags().Uint32(maxStepsFlag.name, 0, "Maximum number of steps after which program will stop any remaining calculations. Unlike timeout it doesn't depend on machine speed. Set this value to 0 if you don't want any step limit.")
 	rootCmd.Flags().StringP(output.name, output.short, "", "Use this flag to specify filepath where output should be placed. If you want to use `stdout` leave this option empty.")
 	rootCmd.Flags().BoolP(includeCalculations.name, includeCalculations.short, false, "If set to true all calculations done by automaton will be written to output.")
+	rootCmd.Flags().StringP(formatFlag.name, formatFlag.short, "text", "Output format, either 'text' or 'json'. With 'json' result (and statistics) are written as JSON objects and calculations as JSON Lines, one object per step.")
+	rootCmd.Flags().Bool(statsFlag.name, false, "If set to true statistics of the run (number of steps, elapsed time, max stack depth for PA, tape cells used and head travel for TM) will be written to output after the result, or before the error if the run is stopped.")
+	addInputFlags(rootCmd)
 }
 
-func runRootCmd(cmd *cobra.Command, args []string) error {
-	// automaton type
-	aType := args[0]
+// addInputFlags adds flags allowing to provide input separately from the source, see `externalInput`
+func addInputFlags(cmd *cobra.Command) {
+	cmd.Flags().String(inputFlag.name, "", "Input (or initial tape for TM) as whitespace separated symbols. It replaces input section from the source file, which can be omitted then.")
+	cmd.Flags().String(inputFileFlag.name, "", "Path to the file with input (or initial tape for TM) as whitespace separated symbols. Use '-' to read it from stdin. It replaces input section from the source file, which can be omitted then.")
+	cmd.MarkFlagsMutuallyExclusive(inputFlag.name, inputFileFlag.name)
+}
 
-	// source
-	b, err := os.ReadFile(args[1])
+func runRootCmd(cmd *cobra.Command, args []string) error {
+	// automaton type and source
+	aType, source, err := sourceArgs(cmd, args)
 	if err != nil {
 		return err
 	}
-	source := string(b)
 
 	// parse automaton options
 	opts, cleanupFunc, err := automatonOptions(cmd)
@@ -79,37 +107,33 @@ func runRootCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// start processing
-	err = processAutomaton(aType, source, opts, timeout)
+	// parse input provided separately from the source
+	input, err := externalInput(cmd)
+	if err != nil {
+		return err
+	}
+
+	// parse stats
+	stats, err := cmd.Flags().GetBool(statsFlag.name)
 	if err != nil {
-		fmt.Printf("%s\n", err.Error())
+		return err
 	}
-	return nil
+
+	// start processing
+	return processAutomaton(aType, source, input, opts, timeout, stats)
 }
 
 func automatonOptions(cmd *cobra.Command) (automaton.AutomatonOptions, func(), error) {
 	opts := automaton.AutomatonOptions{}
-	cleanupFunc := func() {}
+	var cleanupFunc func()
 	// output
 	output, err := cmd.Flags().GetString(output.name)
 	if err != nil {
 		return opts, nil, err
 	}
-	if output == "" {
-		opts.Output = os.Stdout
-	} else {
-		err := os.MkdirAll(filepath.Dir(output), 0777)
-		if err != nil {
-			return opts, nil, err
-		}
-		f, err := os.Create(output)
-		if err != nil {
-			return opts, nil, err
-		}
-		opts.Output = f
-		cleanupFunc = func() {
-			f.Close()
-		}
+	opts.Output, cleanupFunc, err = openOutput(output)
+	if err != nil {
+		return opts, nil, err
 	}
 	// include calculations
 	ic, err := cmd.Flags().GetBool(includeCalculations.name)
@@ -117,17 +141,139 @@ func automatonOptions(cmd *cobra.Command) (automaton.AutomatonOptions, func(), e
 		return opts, nil, err
 	}
 	opts.IncludeCalculations = ic
+	// max steps
+	maxSteps, err := cmd.Flags().GetUint32(maxStepsFlag.name)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.MaxSteps = int(maxSteps)
+	// format
+	format, err := cmd.Flags().GetString(formatFlag.name)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Format, err = parseOutputFormat(format)
+	if err != nil {
+		return opts, nil, err
+	}
 	return opts, cleanupFunc, nil
 }
 
-func getCompiler(tokens []lexer.Token, aType string) (compiler.Compiler, error) {
+// openOutput returns writer for the file at `path` (creating missing directories) and function closing it,
+// if `path` is empty it returns stdout
+func openOutput(path string) (io.Writer, func(), error) {
+	if path == "" {
+		return os.Stdout, func() {}, nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0777)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanupFunc := func() {
+		f.Close()
+	}
+	return f, cleanupFunc, nil
+}
+
+func parseOutputFormat(format string) (automaton.OutputFormat, error) {
+	switch strings.ToLower(format) {
+	case "text":
+		return automaton.TextFormat, nil
+	case "json":
+		return automaton.JSONFormat, nil
+	default:
+		return automaton.TextFormat, fmt.Errorf("unsupported output format: '%s'", format)
+	}
+}
+
+// externalInput returns input provided with `input` or `input-file` flag, or nil if none of them was used
+func externalInput(cmd *cobra.Command) (*sourceFile, error) {
+	if cmd.Flags().Changed(inputFlag.name) {
+		input, err := cmd.Flags().GetString(inputFlag.name)
+		if err != nil {
+			return nil, err
+		}
+		return newSourceString(input), nil
+	}
+	if cmd.Flags().Changed(inputFileFlag.name) {
+		path, err := cmd.Flags().GetString(inputFileFlag.name)
+		if err != nil {
+			return nil, err
+		}
+		if path == "-" && sourcePath(cmd.Flags().Args()) == "-" {
+			return nil, errors.New("stdin can't be used for both the source and the input")
+		}
+		return newSourceFile(cmd, path), nil
+	}
+	return nil, nil
+}
+
+// sourceArgs returns automaton type and source given with arguments `[AUTOMATON_TYPE] PATH`, if the type is
+// omitted it's detected with `detectType`
+func sourceArgs(cmd *cobra.Command, args []string) (string, *sourceFile, error) {
+	path := sourcePath(args)
+	source := newSourceFile(cmd, path)
+	extType := extensionType(path)
+	if len(args) == 1 {
+		aType, err := detectType(source, extType)
+		return aType, source, err
+	}
+	// Type declared in the header is checked by the compiler
+	if extType != "" && !strings.EqualFold(args[0], extType) {
+		return "", nil, fmt.Errorf("automaton type %s doesn't match the extension of the source file %s", args[0], path)
+	}
+	return args[0], source, nil
+}
+
+// sourcePath returns path of the source, the last of the arguments `[AUTOMATON_TYPE] PATH`
+func sourcePath(args []string) string {
+	return args[len(args)-1]
+}
+
+// extensionType returns automaton type indicated by extension of the file at `path`, or an empty string if
+// the extension isn't one of .dfa, .pa, .tm
+func extensionType(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".dfa", ".pa", ".tm":
+		return strings.ToUpper(ext[1:])
+	default:
+		return ""
+	}
+}
+
+// detectType returns automaton type declared in the header of the source (e.g. `type TM;`), or `extType`, the type
+// indicated by extension of the source file, if there is no header. Only the beginning of the source is read.
+func detectType(source *sourceFile, extType string) (string, error) {
+	r, err := source.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	declared := compiler.DeclaredType(lexer.NewReaderLexer(r))
+	if declared != "" && extType != "" && !strings.EqualFold(declared, extType) {
+		return "", fmt.Errorf("automaton type %s declared in the source doesn't match the extension of the source file", declared)
+	}
+	if declared != "" {
+		return declared, nil
+	}
+	if extType != "" {
+		return extType, nil
+	}
+	return "", errors.New("unknown automaton type, provide AUTOMATON_TYPE argument, start the source with a header like 'type TM;' or use one of the extensions: .dfa, .pa, .tm")
+}
+
+func getCompiler(r lexer.TokenReader, aType string) (compiler.Compiler, error) {
 	switch strings.ToLower(aType) {
 	case "dfa":
-		return compiler.NewDeterministicFiniteAutomatonCompiler(tokens), nil
+		return compiler.NewDeterministicFiniteAutomatonCompilerFromReader(r), nil
 	case "tm":
-		return compiler.NewTuringMachineCompiler(tokens), nil
+		return compiler.NewTuringMachineCompilerFromReader(r), nil
 	case "pa":
-		return compiler.NewPushdownAutomatonCompiler(tokens), nil
+		return compiler.NewPushdownAutomatonCompilerFromReader(r), nil
 	default:
 		return nil, fmt.Errorf("unsupported automaton type: '%s'", aType)
 	}
@@ -142,26 +288,140 @@ func createContextWithTimeout(timeout uint32) (context.Context, context.CancelFu
 	return context.Background(), emptyFun
 }
 
-func processAutomaton(aType string, source string, opts automaton.AutomatonOptions, timeout uint32) error {
-	l := lexer.NewLexer(source)
-	tokens, err := l.ScanTokens()
+// compileSource compiles automaton, tokens are passed to the compiler as soon as they are scanned from the source
+// file. Only sources defining macros or using named sections are held in memory all at once, as they are expanded
+// or reordered first. The source is read again if it contains errors, to highlight them.
+func compileSource(aType string, source *sourceFile) (compiler.Compiler, automaton.Automaton, error) {
+	r, err := source.open()
 	if err != nil {
-		return fmt.Errorf("error during lexing stage: %s", err.Error())
+		return nil, nil, err
 	}
-	c, err := getCompiler(tokens, aType)
+	defer r.Close()
+	c, err := getCompiler(macro.NewExpander(lexer.NewReaderLexer(r)), aType)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	a, err := c.Compile()
+	if err == nil {
+		return c, a, nil
+	}
+	text := source.text()
+	// Errors can end with lexing error, so they are checked first
+	var errs compiler.Errors
+	if errors.As(err, &errs) {
+		highlighted := make([]error, 0, len(errs))
+		for _, e := range errs {
+			highlighted = append(highlighted, highlightErr(text, e))
+		}
+		return nil, nil, fmt.Errorf("%d errors during compiling stage:\n%w", len(errs), errors.Join(highlighted...))
+	}
+	var lexErr lexer.LexError
+	if errors.As(err, &lexErr) {
+		return nil, nil, fmt.Errorf("error during lexing stage: %w", highlightErr(text, err))
+	}
+	return nil, nil, fmt.Errorf("error during compiling stage: %w", highlightErr(text, err))
+}
+
+// compileExternalInput creates automaton compiled by `c` that will process `input`, the input is read like
+// the source in `compileSource`
+func compileExternalInput(c compiler.Compiler, input *sourceFile) (automaton.Automaton, error) {
+	r, err := input.open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	a, err := c.CompileInputFromReader(lexer.NewReaderLexer(r))
+	if err == nil {
+		return a, nil
+	}
+	var lexErr lexer.LexError
+	if errors.As(err, &lexErr) {
+		return nil, fmt.Errorf("error during lexing input: %w", highlightErr(input.text(), err))
+	}
+	return nil, fmt.Errorf("error during compiling input: %w", highlightErr(input.text(), err))
+}
+
+// highlightedError wraps error found in the source, its message is followed by the line of the source with
+// the erroneous fragment marked
+type highlightedError struct {
+	err       error
+	highlight string
+}
+
+func (e highlightedError) Error() string {
+	return e.err.Error() + "\n" + e.highlight
+}
+
+func (e highlightedError) Unwrap() error {
+	return e.err
+}
+
+// highlightErr adds the marked fragment of the `source` to the error, if position of the error is known and
+// the source could be read
+func highlightErr(source string, err error) error {
+	if source == "" {
+		return err
+	}
+	var lexErr lexer.LexError
+	if errors.As(err, &lexErr) {
+		return highlightedError{err: err, highlight: lexer.Highlight(source, lexErr.Span)}
+	}
+	var compileErr compiler.CompileError
+	if errors.As(err, &compileErr) && compileErr.Column > 0 {
+		return highlightedError{err: err, highlight: lexer.Highlight(source, compileErr.Span)}
+	}
+	return err
+}
+
+// compileWithInput compiles automaton, if `input` is not nil it's used instead of the input section from the source
+func compileWithInput(aType string, source *sourceFile, input *sourceFile) (automaton.Automaton, error) {
+	c, a, err := compileSource(aType, source)
 	if err != nil {
-		return fmt.Errorf("error during compiling stage: %s", err.Error())
+		return nil, err
+	}
+	if input != nil {
+		return compileExternalInput(c, input)
+	}
+	return a, nil
+}
+
+// processAutomaton compiles and runs automaton, if `input` is not nil it's used instead of the input
+// section from the source
+func processAutomaton(aType string, source *sourceFile, input *sourceFile, opts automaton.AutomatonOptions, timeout uint32, stats bool) error {
+	a, err := compileWithInput(aType, source, input)
+	if err != nil {
+		return err
 	}
 	ctx, cancelFunc := createContextWithTimeout(timeout)
 	defer cancelFunc()
-	result, err := automaton.Run(ctx, a, opts)
+	result, runStats, err := automaton.RunWithStatistics(ctx, a, opts)
 	if err != nil {
-		return fmt.Errorf("error during running stage: %s", err.Error())
+		// Statistics of stopped runs are written as well, e.g. to see how many steps were made before timeout
+		if stats {
+			if err := writeStatistics(opts.Output, runStats, opts.Format); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("error during running stage: %w", err)
+	}
+	if err := automaton.WriteResult(opts.Output, result, opts.Format); err != nil {
+		return err
+	}
+	if stats {
+		if err := writeStatistics(opts.Output, runStats, opts.Format); err != nil {
+			return err
+		}
+	}
+	if !result.Accepted() {
+		return errRejected
 	}
-	result.SaveResult(opts.Output)
 	return nil
 }
+
+// writeStatistics writes `stats` to `w` as text or as a separate JSON object
+func writeStatistics(w io.Writer, stats automaton.RunStatistics, format automaton.OutputFormat) error {
+	if format == automaton.JSONFormat {
+		return json.NewEncoder(w).Encode(stats)
+	}
+	return stats.SaveStatistics(w)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sourceFile is the source or the input of the automaton. It's read in chunks by the lexer, so files are never
+// held in memory, unless an error found in them is highlighted. Content that can't be read again (stdin or
+// a command line argument) is the exception: the part read so far is kept to read it again after detecting
+// type of the automaton and to highlight errors.
+type sourceFile struct {
+	// path of the file, empty if the content is read from `r`
+	path string
+	r    io.Reader
+	// read holds the part of `r` read so far
+	read *bytes.Buffer
+}
+
+// newSourceFile returns the file under `path`, or stdin if `path` is '-'
+func newSourceFile(cmd *cobra.Command, path string) *sourceFile {
+	if path == "-" {
+		return &sourceFile{r: cmd.InOrStdin(), read: new(bytes.Buffer)}
+	}
+	return &sourceFile{path: path}
+}
+
+// newSourceString returns source with the given content, e.g. input from the command line
+func newSourceString(content string) *sourceFile {
+	return &sourceFile{r: strings.NewReader(content), read: new(bytes.Buffer)}
+}
+
+// open returns reader of the whole content, it can be called many times
+func (f *sourceFile) open() (io.ReadCloser, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	// Bytes already read are not modified by the following writes, so they can be read while `read` grows
+	return io.NopCloser(io.MultiReader(bytes.NewReader(f.read.Bytes()), io.TeeReader(f.r, f.read))), nil
+}
+
+// text returns the content used to highlight errors, or an empty string if it can't be read
+func (f *sourceFile) text() string {
+	if f.path == "" {
+		return f.read.String()
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"automata-compiler/pkg/automaton"
+	"automata-compiler/pkg/compiler"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// testCmd runs test cases embedded in the source file
+var testCmd = &cobra.Command{
+	Use:   "test [AUTOMATON_TYPE] PATH_TO_INPUT_FILE",
+	Short: "Run test cases from the tests section of the source file",
+	Long: `Compiles the automaton and runs every test case listed in the tests section of the source file.
+Each test case is reported as PASS or FAIL followed by a summary. The command exits with non-zero
+code when at least one test case fails.`,
+	RunE:         runTestCmd,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+}
+
+func init() {
+	testCmd.Flags().Uint32P(timeoutFlag.name, timeoutFlag.short, 3000, "Timeout in miliseconds for every single test case. Set this value to 0 if you don't want any timeout.")
+	testCmd.Flags().Uint32(maxStepsFlag.name, 0, "Maximum number of steps for every single test case. Set this value to 0 if you don't want any step limit.")
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTestCmd(cmd *cobra.Command, args []string) error {
+	aType, source, err := sourceArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetUint32(timeoutFlag.name)
+	if err != nil {
+		return err
+	}
+	maxSteps, err := cmd.Flags().GetUint32(maxStepsFlag.name)
+	if err != nil {
+		return err
+	}
+	opts := automaton.AutomatonOptions{MaxSteps: int(maxSteps)}
+	c, _, err := compileSource(aType, source)
+	if err != nil {
+		return err
+	}
+	tests := c.Tests()
+	failed := runTests(tests, opts, timeout, cmd.OutOrStdout())
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test cases failed", failed, len(tests))
+	}
+	return nil
+}
+
+// runTests runs every test case and writes report to `w`, it returns number of failed test cases
+func runTests(tests []compiler.TestCase, opts automaton.AutomatonOptions, timeout uint32, w io.Writer) int {
+	failed := 0
+	for _, tc := range tests {
+		err := runTestCase(tc, opts, timeout)
+		input := strings.Join(tc.Input, " ")
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL [Line %d] (%s): %s\n", tc.Line, input, err.Error())
+		} else {
+			fmt.Fprintf(w, "PASS [Line %d] (%s)\n", tc.Line, input)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(tests)-failed, failed)
+	return failed
+}
+
+func runTestCase(tc compiler.TestCase, opts automaton.AutomatonOptions, timeout uint32) error {
+	ctx, cancelFunc := createContextWithTimeout(timeout)
+	defer cancelFunc()
+	result, err := automaton.Run(ctx, tc.Automaton, opts)
+	if err != nil {
+		return err
+	}
+	return tc.Verify(result)
+}
@@ -66,4 +66,11 @@ qAcc;
 ;
 
 # Initial tape
-1 1 1 1 1 1 1 ; # 7, so result should be 3 (111 in unary)
\ No newline at end of file
+1 1 1 1 1 1 1 ; # 7, so result should be 3 (111 in unary)
+
+# Tests
+tests
+(1 1 1 1 1 1 1) > (1 1 1) # ceil(7/3) = 3
+(1 1 1 1 1 1) > (1 1) # ceil(6/3) = 2
+(1) > (1)
+;
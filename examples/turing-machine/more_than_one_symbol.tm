@@ -4,4 +4,7 @@ qZero;
 qOne;
 1;
 (qZero, 1) > (qOne, 1, R);
-1;
\ No newline at end of file
+1;
+tests
+(1) > (1)
+;
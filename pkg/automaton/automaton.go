@@ -2,9 +2,13 @@ package automaton
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"time"
 )
 
 type State struct {
@@ -21,65 +25,296 @@ type Automaton interface {
 	calculationsFinished() bool
 	result() AutomatonResult
 	makeMove() error
+	// updateStatistics updates automaton specific statistics, it's called before the first move and after every move
+	updateStatistics(stats *RunStatistics)
+	// head returns name of the current state and name of the symbol under the head (next input symbol for DFA
+	// and PA), symbol is empty if there is nothing left to read
+	head() (stateName string, symbolName string)
+	// record returns the part of the configuration that can be changed by the next move
+	record() moveRecord
+	// restore brings back the configuration from before the move described by `r`, it must be called
+	// in reverse order of moves
+	restore(r moveRecord)
+	// storage returns symbols from the stack for PA or from the tape for TM, it's nil for DFA. Returned
+	// slice must not be modified.
+	storage() []string
+}
+
+// moveRecord holds only the part of the configuration changed by a single move, so history of long
+// calculations can be kept without copying the whole stack or tape in every step
+type moveRecord struct {
+	stateName string
+	// it is the input or tape iterator
+	it int
+	// symbolName is the symbol removed from the top of the stack for PA or overwritten on the tape for TM
+	symbolName string
+	// length is the stack or tape length
+	length int
 }
 
 type AutomatonCurrentCalculationsState interface {
 	SaveState(w io.Writer) error
+	json.Marshaler
 }
 
 type AutomatonResult interface {
 	SaveResult(w io.Writer) error
+	json.Marshaler
+	// Accepted reports whether automaton finished calculations in accepting state
+	Accepted() bool
 }
 
 func Run(ctx context.Context, a Automaton, opts AutomatonOptions) (AutomatonResult, error) {
+	result, _, err := RunWithStatistics(ctx, a, opts)
+	return result, err
+}
+
+// RunWithStatistics works the same as `Run`, but it also returns statistics of the run. Statistics are
+// returned even if calculations fail, describing the run up to the moment of failure.
+func RunWithStatistics(ctx context.Context, a Automaton, opts AutomatonOptions) (AutomatonResult, RunStatistics, error) {
 	err := opts.validate()
 	if err != nil {
 		panic(err)
 	}
 	var zero AutomatonResult
+	stats := RunStatistics{}
+	start := time.Now()
+	a.updateStatistics(&stats)
+	cd := newCycleDetector(a)
 	for {
 		select {
 		case <-ctx.Done():
-			return zero, errors.New("timeout reached")
+			stats.Elapsed = time.Since(start)
+			return zero, stats, TimeoutError{Steps: stats.Steps}
 		default:
 			if opts.IncludeCalculations {
-				err := writeCurrentState(a, opts.Output)
+				err := writeCurrentState(a, opts.Output, opts.Format)
 				if err != nil {
-					return zero, err
+					stats.Elapsed = time.Since(start)
+					return zero, stats, err
 				}
 			}
+			if opts.Trace != nil {
+				opts.Trace(a.currentCalculationsState())
+			}
 			if a.calculationsFinished() {
-				return a.result(), nil
+				stats.Elapsed = time.Since(start)
+				return a.result(), stats, nil
+			}
+			if opts.MaxSteps > 0 && stats.Steps >= opts.MaxSteps {
+				stats.Elapsed = time.Since(start)
+				return zero, stats, StepLimitExceededError{Steps: stats.Steps, Configuration: a.currentCalculationsState()}
 			}
 			if err := a.makeMove(); err != nil {
-				return zero, err
+				stats.Elapsed = time.Since(start)
+				return zero, stats, err
+			}
+			stats.Steps++
+			a.updateStatistics(&stats)
+			if period := cd.check(a); period > 0 {
+				stats.Elapsed = time.Since(start)
+				return zero, stats, InfiniteLoopError{Step: stats.Steps, Period: period}
 			}
 		}
 	}
 }
 
-func writeCurrentState(a Automaton, w io.Writer) error {
+// RunStatistics describes time and space used by automaton during calculations
+type RunStatistics struct {
+	// Steps is the number of moves made by automaton
+	Steps int `json:"steps"`
+	// Elapsed is the wall-clock time of calculations
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// MaxStackDepth is the maximum number of symbols on the stack, it's set only for PA
+	MaxStackDepth int `json:"max_stack_depth,omitempty"`
+	// TapeCellsUsed is the number of tape cells containing input or visited by the head, it's set only for TM
+	TapeCellsUsed int `json:"tape_cells_used,omitempty"`
+	// HeadTravel is the total number of cells the head moved by, it's set only for TM
+	HeadTravel int `json:"head_travel,omitempty"`
+}
+
+// SaveStatistics writes statistics to `w`, automaton specific values are written only if they are set
+func (rs RunStatistics) SaveStatistics(w io.Writer) error {
+	out := fmt.Sprintf("steps: %d, elapsed: %s", rs.Steps, rs.Elapsed)
+	if rs.MaxStackDepth > 0 {
+		out += fmt.Sprintf(", max stack depth: %d", rs.MaxStackDepth)
+	}
+	if rs.TapeCellsUsed > 0 {
+		out += fmt.Sprintf(", tape cells used: %d, head travel: %d", rs.TapeCellsUsed, rs.HeadTravel)
+	}
+	_, err := w.Write([]byte(out + "\n"))
+	return err
+}
+
+// TimeoutError is returned by `Run` when context is done before automaton finishes calculations
+type TimeoutError struct {
+	// Steps is the number of steps made before calculations were stopped
+	Steps int
+}
+
+func (e TimeoutError) Error() string {
+	return "timeout reached"
+}
+
+// MissingTransitionError is returned when there is no transition for the current configuration, so calculations
+// can't be continued
+type MissingTransitionError struct {
+	StateName  string
+	SymbolName string
+	// StackSymbolName is the symbol from the top of the stack, it's set only for PA
+	StackSymbolName string
+}
+
+func (e MissingTransitionError) Error() string {
+	if e.StackSymbolName != "" {
+		return fmt.Sprintf("cannot continue calculations, missing transition for state %s, symbol %s and stack symbol %s", e.StateName, e.SymbolName, e.StackSymbolName)
+	}
+	return fmt.Sprintf("cannot continue calculations, missing transition for state %s and symbol %s", e.StateName, e.SymbolName)
+}
+
+// TapeUnderflowError is returned when TM moves left from the first cell of the tape
+type TapeUnderflowError struct {
+	// StateName and SymbolName identify the transition that moved the head out of the tape
+	StateName  string
+	SymbolName string
+}
+
+func (e TapeUnderflowError) Error() string {
+	return "cannot continue calculations, turing machine went out of tape"
+}
+
+// EmptyStackError is returned when PA tries to make a move with an empty stack
+type EmptyStackError struct {
+	StateName string
+}
+
+func (e EmptyStackError) Error() string {
+	return "stack is empty"
+}
+
+// StepLimitExceededError is returned by `Run` when automaton doesn't finish calculations within
+// `MaxSteps` steps
+type StepLimitExceededError struct {
+	// Steps is the number of steps made before calculations were stopped
+	Steps int
+	// Configuration is the automaton configuration at the moment calculations were stopped
+	Configuration AutomatonCurrentCalculationsState
+}
+
+func (e StepLimitExceededError) Error() string {
+	var sb strings.Builder
+	e.Configuration.SaveState(&sb)
+	return fmt.Sprintf("step limit exceeded after %d steps, last configuration:\n%s", e.Steps, strings.TrimRight(sb.String(), "\n"))
+}
+
+// InfiniteLoopError is returned by `Run` when automaton reaches the same configuration again, which means
+// it will repeat the same moves forever
+type InfiniteLoopError struct {
+	// Step is the number of steps made when the loop was detected
+	Step int
+	// Period is the number of steps after which configuration repeats
+	Period int
+}
+
+func (e InfiniteLoopError) Error() string {
+	return fmt.Sprintf("infinite loop detected at step %d, repeats every %d steps", e.Step, e.Period)
+}
+
+// cycleDetector finds repeated configurations using Brent's algorithm: the current configuration is compared
+// only with one saved configuration, which is replaced after 1, 2, 4, 8, ... steps. It keeps memory usage
+// constant and detects every loop within a few of its periods.
+type cycleDetector struct {
+	power  int
+	period int
+	// savedRecord is compared first as it's cheap, `savedStorage` only if records are the same
+	savedRecord  moveRecord
+	savedStorage []string
+}
+
+func newCycleDetector(a Automaton) cycleDetector {
+	cd := cycleDetector{power: 1}
+	cd.save(a)
+	return cd
+}
+
+// check must be called after every move, it returns period of the loop if automaton is in the saved
+// configuration, otherwise it returns 0
+func (cd *cycleDetector) check(a Automaton) int {
+	cd.period++
+	if a.record() == cd.savedRecord && slices.Equal(a.storage(), cd.savedStorage) {
+		return cd.period
+	}
+	if cd.period == cd.power {
+		cd.save(a)
+		cd.power *= 2
+		cd.period = 0
+	}
+	return 0
+}
+
+func (cd *cycleDetector) save(a Automaton) {
+	cd.savedRecord = a.record()
+	cd.savedStorage = slices.Clone(a.storage())
+}
+
+func writeCurrentState(a Automaton, w io.Writer, format OutputFormat) error {
 	cs := a.currentCalculationsState()
+	if format == JSONFormat {
+		return json.NewEncoder(w).Encode(cs)
+	}
 	err := cs.SaveState(w)
 	return err
 }
 
+// OutputFormat describes how results and calculations are written to the output
+type OutputFormat int
+
+const (
+	// TextFormat is human readable format, used by `SaveState` and `SaveResult`
+	TextFormat OutputFormat = iota
+	// JSONFormat writes every result and calculations state as a single line JSON object
+	JSONFormat
+)
+
+// WriteResult writes `r` to `w` in given format
+func WriteResult(w io.Writer, r AutomatonResult, format OutputFormat) error {
+	if format == JSONFormat {
+		return json.NewEncoder(w).Encode(r)
+	}
+	return r.SaveResult(w)
+}
+
 type AutomatonOptions struct {
 	Output              io.Writer
 	IncludeCalculations bool
+	// MaxSteps is the maximum number of moves automaton can make, 0 means no limit
+	MaxSteps int
+	// Format of calculations written when `IncludeCalculations` is enabled
+	Format OutputFormat
+	// Trace, if set, is called with every calculations state, the same ones that are written to `Output`
+	// when `IncludeCalculations` is enabled
+	Trace func(cs AutomatonCurrentCalculationsState)
 }
 
 func (opts AutomatonOptions) validate() error {
 	if opts.IncludeCalculations && opts.Output == nil {
 		return errors.New("field `Output` must be set when `IncludeCalculations` is enabled")
 	}
+	if opts.MaxSteps < 0 {
+		return errors.New("field `MaxSteps` cannot be negative")
+	}
 	return nil
 }
 
-func symbolsToString(symbols []Symbol) string {
-	symbolsStr := make([]string, 0, len(symbols))
+// symbolsToNames returns names of `symbols`, it never returns nil so empty list is encoded as `[]` in JSON
+func symbolsToNames(symbols []Symbol) []string {
+	names := make([]string, 0, len(symbols))
 	for _, v := range symbols {
-		symbolsStr = append(symbolsStr, v.Name)
+		names = append(names, v.Name)
 	}
-	return strings.Join(symbolsStr, "|")
+	return names
+}
+
+func symbolsToString(symbols []Symbol) string {
+	return strings.Join(symbolsToNames(symbols), "|")
 }
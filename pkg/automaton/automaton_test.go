@@ -0,0 +1,152 @@
+package automaton
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSaveStatistics(t *testing.T) {
+	data := []struct {
+		name     string
+		stats    RunStatistics
+		expected string
+	}{
+		{
+			"only common statistics",
+			RunStatistics{Steps: 10, Elapsed: time.Millisecond},
+			"steps: 10, elapsed: 1ms\n",
+		},
+		{
+			"pushdown automaton statistics",
+			RunStatistics{Steps: 10, Elapsed: time.Millisecond, MaxStackDepth: 4},
+			"steps: 10, elapsed: 1ms, max stack depth: 4\n",
+		},
+		{
+			"turing machine statistics",
+			RunStatistics{Steps: 0, Elapsed: time.Millisecond, TapeCellsUsed: 3, HeadTravel: 0},
+			"steps: 0, elapsed: 1ms, tape cells used: 3, head travel: 0\n",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			var result strings.Builder
+			d.stats.SaveStatistics(&result)
+			if result.String() != d.expected {
+				t.Errorf("invalid result string, expected:\n%s, got:\n%s", d.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestRunErrorTypes(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	data := []struct {
+		name     string
+		ctx      context.Context
+		a        Automaton
+		expected error
+	}{
+		{
+			"missing transition in DFA",
+			context.Background(),
+			&DeterministicFiniteAutomaton{
+				States:       map[string]State{"qA": {Name: "qA"}},
+				Symbols:      map[string]Symbol{"a": {Name: "a"}},
+				CurrentState: "qA",
+				Input:        []string{"a"},
+				Transitions:  DFATransitionFunction{},
+			},
+			MissingTransitionError{StateName: "qA", SymbolName: "a"},
+		},
+		{
+			"missing transition in PA",
+			context.Background(),
+			&PushdownAutomaton{
+				States:       map[string]State{"qA": {Name: "qA"}},
+				Symbols:      map[string]Symbol{InputEndSymbol.Name: InputEndSymbol, StackStartSymbol.Name: StackStartSymbol},
+				CurrentState: "qA",
+				Input:        []string{InputEndSymbol.Name},
+				Stack:        []string{StackStartSymbol.Name},
+				Transitions:  map[PATransitionKey]PATransitionValue{},
+			},
+			MissingTransitionError{StateName: "qA", SymbolName: InputEndSymbol.Name, StackSymbolName: StackStartSymbol.Name},
+		},
+		{
+			"empty stack in PA",
+			context.Background(),
+			&PushdownAutomaton{
+				States:       map[string]State{"qA": {Name: "qA"}},
+				Symbols:      map[string]Symbol{InputEndSymbol.Name: InputEndSymbol, StackStartSymbol.Name: StackStartSymbol},
+				CurrentState: "qA",
+				Input:        []string{InputEndSymbol.Name},
+				Stack:        []string{},
+				Transitions:  map[PATransitionKey]PATransitionValue{},
+			},
+			EmptyStackError{StateName: "qA"},
+		},
+		{
+			"tape underflow in TM",
+			context.Background(),
+			&TuringMachine{
+				States:       map[string]State{"qA": {Name: "qA"}, "qB": {Name: "qB"}},
+				Symbols:      map[string]Symbol{BlankSymbol.Name: BlankSymbol},
+				CurrentState: "qA",
+				Tape:         []string{BlankSymbol.Name},
+				Transitions: map[TMTransitionKey]TMTransitionValue{
+					{StateName: "qA", SymbolName: BlankSymbol.Name}: {StateName: "qB", SymbolName: BlankSymbol.Name, Move: TapeMoveLeft},
+				},
+			},
+			TapeUnderflowError{StateName: "qA", SymbolName: BlankSymbol.Name},
+		},
+		{
+			"timeout",
+			cancelled,
+			&TuringMachine{
+				States:       map[string]State{"qA": {Name: "qA"}},
+				Symbols:      map[string]Symbol{BlankSymbol.Name: BlankSymbol},
+				CurrentState: "qA",
+				Tape:         []string{BlankSymbol.Name},
+				Transitions:  map[TMTransitionKey]TMTransitionValue{},
+			},
+			TimeoutError{Steps: 0},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			_, err := Run(d.ctx, d.a, AutomatonOptions{})
+			// Target of the same type as expected error
+			var result error
+			switch d.expected.(type) {
+			case MissingTransitionError:
+				var target MissingTransitionError
+				if errors.As(err, &target) {
+					result = target
+				}
+			case EmptyStackError:
+				var target EmptyStackError
+				if errors.As(err, &target) {
+					result = target
+				}
+			case TapeUnderflowError:
+				var target TapeUnderflowError
+				if errors.As(err, &target) {
+					result = target
+				}
+			case TimeoutError:
+				var target TimeoutError
+				if errors.As(err, &target) {
+					result = target
+				}
+			}
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Errorf("invalid error %v:\n%s", err, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package automaton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCalculationsFinished is returned by `Debugger.Step` when automaton has already finished calculations
+var ErrCalculationsFinished = errors.New("calculations already finished")
+
+// Breakpoint stops `Debugger.Continue` when automaton reaches matching configuration
+type Breakpoint interface {
+	hit(stateName string, symbolName string, step int) bool
+	String() string
+}
+
+// StateBreakpoint is hit when automaton enters state `StateName`
+type StateBreakpoint struct {
+	StateName string
+}
+
+func (b StateBreakpoint) hit(stateName string, _ string, _ int) bool {
+	return b.StateName == stateName
+}
+
+func (b StateBreakpoint) String() string {
+	return fmt.Sprintf("state %s", b.StateName)
+}
+
+// SymbolBreakpoint is hit when symbol `SymbolName` is under the head (for DFA and PA it's the next input symbol)
+type SymbolBreakpoint struct {
+	SymbolName string
+}
+
+func (b SymbolBreakpoint) hit(_ string, symbolName string, _ int) bool {
+	return b.SymbolName == symbolName
+}
+
+func (b SymbolBreakpoint) String() string {
+	return fmt.Sprintf("symbol %s", b.SymbolName)
+}
+
+// StepBreakpoint is hit after automaton makes `Step` moves
+type StepBreakpoint struct {
+	Step int
+}
+
+func (b StepBreakpoint) hit(_ string, _ string, step int) bool {
+	return b.Step == step
+}
+
+func (b StepBreakpoint) String() string {
+	return fmt.Sprintf("step %d", b.Step)
+}
+
+// Debugger runs automaton one move at a time, unlike `Run` it allows to stop calculations at breakpoints
+// and inspect configuration of the automaton between moves. It records history of all moves, so calculations
+// can be also stepped backwards.
+type Debugger struct {
+	a Automaton
+	// history holds record of every move made, the number of moves is the current step
+	history     []moveRecord
+	breakpoints []Breakpoint
+}
+
+func NewDebugger(a Automaton) *Debugger {
+	return &Debugger{a: a, history: make([]moveRecord, 0), breakpoints: make([]Breakpoint, 0)}
+}
+
+// AddBreakpoint adds breakpoint checked by `Continue` after every move
+func (d *Debugger) AddBreakpoint(b Breakpoint) {
+	d.breakpoints = append(d.breakpoints, b)
+}
+
+// RemoveBreakpoint removes breakpoint with index `i` in the list returned by `Breakpoints`
+func (d *Debugger) RemoveBreakpoint(i int) error {
+	if i < 0 || i >= len(d.breakpoints) {
+		return fmt.Errorf("breakpoint %d doesn't exist", i)
+	}
+	d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+	return nil
+}
+
+func (d *Debugger) Breakpoints() []Breakpoint {
+	return d.breakpoints
+}
+
+// Steps returns the number of moves made by automaton
+func (d *Debugger) Steps() int {
+	return len(d.history)
+}
+
+// Finished reports whether automaton finished calculations
+func (d *Debugger) Finished() bool {
+	return d.a.calculationsFinished()
+}
+
+// State returns the current configuration of the automaton
+func (d *Debugger) State() AutomatonCurrentCalculationsState {
+	return d.a.currentCalculationsState()
+}
+
+// Result returns result of the calculations, it's available only after automaton finished calculations
+func (d *Debugger) Result() (AutomatonResult, error) {
+	if !d.Finished() {
+		var zero AutomatonResult
+		return zero, errors.New("calculations not finished yet")
+	}
+	return d.a.result(), nil
+}
+
+// Step makes a single move. If the move fails, configuration from before the move is restored.
+func (d *Debugger) Step() error {
+	if d.Finished() {
+		return ErrCalculationsFinished
+	}
+	r := d.a.record()
+	if err := d.a.makeMove(); err != nil {
+		d.a.restore(r)
+		return err
+	}
+	d.history = append(d.history, r)
+	return nil
+}
+
+// Back undoes the last move
+func (d *Debugger) Back() error {
+	if len(d.history) == 0 {
+		return errors.New("already at the first step")
+	}
+	d.a.restore(d.history[len(d.history)-1])
+	d.history = d.history[:len(d.history)-1]
+	return nil
+}
+
+// GoTo moves backward or forward to the configuration after `step` moves, breakpoints are ignored
+func (d *Debugger) GoTo(step int) error {
+	if step < 0 {
+		return fmt.Errorf("invalid step: %d", step)
+	}
+	for d.Steps() > step {
+		if err := d.Back(); err != nil {
+			return err
+		}
+	}
+	for d.Steps() < step {
+		if err := d.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HeadFirstReached returns the first step in which the head was at cell `cell` (for DFA and PA it's the position
+// in the input), only steps made so far are searched. It returns false if the head hasn't reached the cell.
+func (d *Debugger) HeadFirstReached(cell int) (int, bool) {
+	for step, r := range d.history {
+		if r.it == cell {
+			return step, true
+		}
+	}
+	if _, it := d.position(); it == cell {
+		return len(d.history), true
+	}
+	return 0, false
+}
+
+// StateLastEntered returns the last step in which automaton entered state `stateName` from a different state
+// (or started in it), only steps made so far are searched. It returns false if the state hasn't been entered.
+func (d *Debugger) StateLastEntered(stateName string) (int, bool) {
+	stateAt := func(step int) string {
+		if step == len(d.history) {
+			current, _ := d.position()
+			return current
+		}
+		return d.history[step].stateName
+	}
+	for step := len(d.history); step >= 0; step-- {
+		if stateAt(step) == stateName && (step == 0 || stateAt(step-1) != stateName) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// position returns the current state and iterator of the automaton
+func (d *Debugger) position() (string, int) {
+	r := d.a.record()
+	return r.stateName, r.it
+}
+
+// Continue makes moves until a breakpoint is hit, automaton finishes calculations, move fails, infinite loop
+// is detected (see `InfiniteLoopError`) or `ctx` is done. It returns the breakpoint that stopped calculations or nil if it was stopped for another reason.
+// At least one move is made, so calling `Continue` again resumes calculations after the breakpoint.
+func (d *Debugger) Continue(ctx context.Context) (Breakpoint, error) {
+	cd := newCycleDetector(d.a)
+	if err := d.Step(); err != nil {
+		return nil, err
+	}
+	for {
+		stateName, symbolName := d.a.head()
+		for _, b := range d.breakpoints {
+			if b.hit(stateName, symbolName, d.Steps()) {
+				return b, nil
+			}
+		}
+		if d.Finished() {
+			return nil, nil
+		}
+		if period := cd.check(d.a); period > 0 {
+			return nil, InfiniteLoopError{Step: d.Steps(), Period: period}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		if err := d.Step(); err != nil {
+			return nil, err
+		}
+	}
+}
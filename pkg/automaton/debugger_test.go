@@ -0,0 +1,320 @@
+package automaton
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newDebuggerTestTM creates TM replacing all `1` symbols with `0` and accepting on the first blank
+func newDebuggerTestTM() *TuringMachine {
+	return &TuringMachine{
+		States: map[string]State{
+			"qStart": {Name: "qStart"},
+			"qZero":  {Name: "qZero"},
+			"qAcc":   {Name: "qAcc", Accepting: true},
+		},
+		CurrentState: "qStart",
+		Symbols: map[string]Symbol{
+			BlankSymbol.Name: BlankSymbol,
+			"0":              {Name: "0"},
+			"1":              {Name: "1"},
+		},
+		Transitions: map[TMTransitionKey]TMTransitionValue{
+			{StateName: "qStart", SymbolName: "0"}: {StateName: "qStart", SymbolName: "0", Move: TapeMoveRight},
+			{StateName: "qStart", SymbolName: "1"}: {StateName: "qZero", SymbolName: "0", Move: TapeMoveRight},
+			{StateName: "qZero", SymbolName: "0"}:  {StateName: "qStart", SymbolName: "0", Move: TapeMoveRight},
+			{StateName: "qZero", SymbolName: "1"}:  {StateName: "qZero", SymbolName: "0", Move: TapeMoveRight},
+			{StateName: "qStart", SymbolName: "B"}: {StateName: "qAcc", SymbolName: "B", Move: TapeMoveRight},
+			{StateName: "qZero", SymbolName: "B"}:  {StateName: "qAcc", SymbolName: "B", Move: TapeMoveRight},
+		},
+		Tape: []string{"0", "1", "1", "0", "1"},
+	}
+}
+
+func TestDebuggerContinue(t *testing.T) {
+	data := []struct {
+		name               string
+		breakpoints        []Breakpoint
+		expectedHits       []Breakpoint
+		expectedSteps      []int
+		expectedFinalSteps int
+	}{
+		{
+			"no breakpoints",
+			nil,
+			[]Breakpoint{nil},
+			[]int{6},
+			6,
+		},
+		{
+			"state breakpoint",
+			[]Breakpoint{StateBreakpoint{StateName: "qZero"}},
+			[]Breakpoint{StateBreakpoint{StateName: "qZero"}, StateBreakpoint{StateName: "qZero"}, StateBreakpoint{StateName: "qZero"}, nil},
+			[]int{2, 3, 5, 6},
+			6,
+		},
+		{
+			"symbol breakpoint",
+			[]Breakpoint{SymbolBreakpoint{SymbolName: "B"}},
+			[]Breakpoint{SymbolBreakpoint{SymbolName: "B"}, SymbolBreakpoint{SymbolName: "B"}},
+			[]int{5, 6},
+			6,
+		},
+		{
+			"step breakpoint",
+			[]Breakpoint{StepBreakpoint{Step: 4}, StepBreakpoint{Step: 100}},
+			[]Breakpoint{StepBreakpoint{Step: 4}, nil},
+			[]int{4, 6},
+			6,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			dbg := NewDebugger(newDebuggerTestTM())
+			for _, b := range d.breakpoints {
+				dbg.AddBreakpoint(b)
+			}
+			hits := make([]Breakpoint, 0)
+			steps := make([]int, 0)
+			for !dbg.Finished() {
+				b, err := dbg.Continue(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				hits = append(hits, b)
+				steps = append(steps, dbg.Steps())
+			}
+			if diff := cmp.Diff(d.expectedHits, hits); diff != "" {
+				t.Errorf("invalid breakpoints hit (-expected +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(d.expectedSteps, steps); diff != "" {
+				t.Errorf("invalid steps (-expected +got):\n%s", diff)
+			}
+			if dbg.Steps() != d.expectedFinalSteps {
+				t.Errorf("expected %d steps, got %d", d.expectedFinalSteps, dbg.Steps())
+			}
+		})
+	}
+}
+
+func TestDebuggerStep(t *testing.T) {
+	dbg := NewDebugger(newDebuggerTestTM())
+	if _, err := dbg.Result(); err == nil {
+		t.Error("expected error before calculations finished")
+	}
+	for i := 0; i < 2; i++ {
+		if err := dbg.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sb strings.Builder
+	if err := dbg.State().SaveState(&sb); err != nil {
+		t.Fatal(err)
+	}
+	expected := "current state: qZero, tape: 0|0|1|0|1\n" + strings.Repeat(" ", 32) + "^\n"
+	if sb.String() != expected {
+		t.Errorf("invalid state, expected:\n%s, got:\n%s", expected, sb.String())
+	}
+	for !dbg.Finished() {
+		if err := dbg.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dbg.Step(); !errors.Is(err, ErrCalculationsFinished) {
+		t.Errorf("expected error: %v, got: %v", ErrCalculationsFinished, err)
+	}
+	result, err := dbg.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Accepted() {
+		t.Error("expected accept, got reject")
+	}
+}
+
+func TestDebuggerStepMissingTransition(t *testing.T) {
+	tm := newDebuggerTestTM()
+	tm.Tape = []string{"1", "1"}
+	delete(tm.Transitions, TMTransitionKey{StateName: "qZero", SymbolName: "1"})
+	dbg := NewDebugger(tm)
+	_, err := dbg.Continue(context.Background())
+	expectedErrMsg := "cannot continue calculations, missing transition for state qZero and symbol 1"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Fatalf("expected error: %s, got: %v", expectedErrMsg, err)
+	}
+	// Configuration from before the failed move is restored, so the move fails again
+	if err := dbg.Step(); err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("expected error: %s, got: %v", expectedErrMsg, err)
+	}
+	if dbg.Steps() != 1 {
+		t.Errorf("expected 1 step, got %d", dbg.Steps())
+	}
+	expectedState := TuringMachineCurrentCalculationsState{
+		State: State{Name: "qZero"},
+		Tape:  []Symbol{{Name: "0"}, {Name: "1"}},
+		It:    1,
+	}
+	if diff := cmp.Diff(expectedState, dbg.State()); diff != "" {
+		t.Errorf("invalid state (-expected +got):\n%s", diff)
+	}
+}
+
+func TestDebuggerBack(t *testing.T) {
+	data := []struct {
+		name string
+		a    Automaton
+	}{
+		{
+			"DFA",
+			&DeterministicFiniteAutomaton{
+				States: map[string]State{
+					"qA": {Name: "qA"},
+					"qB": {Name: "qB", Accepting: true},
+				},
+				CurrentState: "qA",
+				Symbols: map[string]Symbol{
+					"0": {Name: "0"},
+					"1": {Name: "1"},
+				},
+				Transitions: map[DFATransitionKey]DFATransitionValue{
+					{StateName: "qA", SymbolName: "0"}: {StateName: "qA"},
+					{StateName: "qA", SymbolName: "1"}: {StateName: "qB"},
+					{StateName: "qB", SymbolName: "0"}: {StateName: "qA"},
+					{StateName: "qB", SymbolName: "1"}: {StateName: "qB"},
+				},
+				Input: []string{"0", "1", "1", "0"},
+			},
+		},
+		{
+			"PA",
+			&PushdownAutomaton{
+				States: map[string]State{
+					"qA":   {Name: "qA"},
+					"qAcc": {Name: "qAcc", Accepting: true},
+				},
+				CurrentState: "qA",
+				Symbols: map[string]Symbol{
+					"0":                   {Name: "0"},
+					"1":                   {Name: "1"},
+					"X":                   {Name: "X"},
+					InputEndSymbol.Name:   InputEndSymbol,
+					StackStartSymbol.Name: StackStartSymbol,
+				},
+				Transitions: map[PATransitionKey]PATransitionValue{
+					{StateName: "qA", InputSymbolName: "0", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}", "X"}},
+					{StateName: "qA", InputSymbolName: "0", StackSymbolName: "X"}: {StateName: "qA", StackSymbolNames: []string{"X", "X"}},
+					{StateName: "qA", InputSymbolName: "1", StackSymbolName: "X"}: {StateName: "qA", StackSymbolNames: []string{}},
+					{StateName: "qA", InputSymbolName: "{", StackSymbolName: "}"}: {StateName: "qAcc", StackSymbolNames: []string{}},
+				},
+				Input: []string{"0", "0", "1", "1", "{"},
+				Stack: []string{"}"},
+			},
+		},
+		{
+			"TM",
+			newDebuggerTestTM(),
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			dbg := NewDebugger(d.a)
+			states := []AutomatonCurrentCalculationsState{dbg.State()}
+			for !dbg.Finished() {
+				if err := dbg.Step(); err != nil {
+					t.Fatal(err)
+				}
+				states = append(states, dbg.State())
+			}
+			for step := len(states) - 1; step > 0; step-- {
+				if diff := cmp.Diff(states[step], dbg.State()); diff != "" {
+					t.Fatalf("invalid state in step %d (-expected +got):\n%s", step, diff)
+				}
+				if err := dbg.Back(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(states[0], dbg.State()); diff != "" {
+				t.Fatalf("invalid initial state (-expected +got):\n%s", diff)
+			}
+			if err := dbg.Back(); err == nil || err.Error() != "already at the first step" {
+				t.Errorf("expected error: already at the first step, got: %v", err)
+			}
+			// Moves made again after going back must give the same configurations
+			last := len(states) - 1
+			if err := dbg.GoTo(last); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(states[last], dbg.State()); diff != "" {
+				t.Errorf("invalid final state (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDebuggerQueries(t *testing.T) {
+	dbg := NewDebugger(newDebuggerTestTM())
+	for !dbg.Finished() {
+		if err := dbg.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := []struct {
+		name          string
+		query         func() (int, bool)
+		expectedStep  int
+		expectedFound bool
+	}{
+		{"head at start", func() (int, bool) { return dbg.HeadFirstReached(0) }, 0, true},
+		{"head in the middle", func() (int, bool) { return dbg.HeadFirstReached(3) }, 3, true},
+		{"head at the end", func() (int, bool) { return dbg.HeadFirstReached(6) }, 6, true},
+		{"head never reached", func() (int, bool) { return dbg.HeadFirstReached(7) }, 0, false},
+		{"initial state", func() (int, bool) { return dbg.StateLastEntered("qStart") }, 4, true},
+		{"state entered many times", func() (int, bool) { return dbg.StateLastEntered("qZero") }, 5, true},
+		{"current state", func() (int, bool) { return dbg.StateLastEntered("qAcc") }, 6, true},
+		{"state never entered", func() (int, bool) { return dbg.StateLastEntered("qOther") }, 0, false},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			step, found := d.query()
+			if step != d.expectedStep || found != d.expectedFound {
+				t.Errorf("expected (%d, %t), got (%d, %t)", d.expectedStep, d.expectedFound, step, found)
+			}
+		})
+	}
+}
+
+func TestDebuggerRemoveBreakpoint(t *testing.T) {
+	dbg := NewDebugger(newDebuggerTestTM())
+	dbg.AddBreakpoint(StateBreakpoint{StateName: "qZero"})
+	dbg.AddBreakpoint(StepBreakpoint{Step: 1})
+	if err := dbg.RemoveBreakpoint(2); err == nil || err.Error() != "breakpoint 2 doesn't exist" {
+		t.Errorf("expected error: breakpoint 2 doesn't exist, got: %v", err)
+	}
+	if err := dbg.RemoveBreakpoint(0); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Breakpoint{StepBreakpoint{Step: 1}}, dbg.Breakpoints()); diff != "" {
+		t.Errorf("invalid breakpoints (-expected +got):\n%s", diff)
+	}
+}
+
+func TestDebuggerContinueInfiniteLoop(t *testing.T) {
+	tm := newDebuggerTestTM()
+	// Go back to the start of the tape instead of accepting
+	tm.Transitions[TMTransitionKey{StateName: "qZero", SymbolName: "B"}] = TMTransitionValue{StateName: "qStart", SymbolName: "B", Move: TapeMoveLeft}
+	tm.Transitions[TMTransitionKey{StateName: "qStart", SymbolName: "0"}] = TMTransitionValue{StateName: "qZero", SymbolName: "0", Move: TapeMoveRight}
+	tm.Tape = []string{"1"}
+	dbg := NewDebugger(tm)
+	_, err := dbg.Continue(context.Background())
+	var loopErr InfiniteLoopError
+	if !errors.As(err, &loopErr) {
+		t.Fatalf("expected infinite loop error, got: %v", err)
+	}
+	if loopErr.Period != 2 {
+		t.Errorf("expected period 2, got %d", loopErr.Period)
+	}
+}
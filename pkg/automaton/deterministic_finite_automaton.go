@@ -1,6 +1,7 @@
 package automaton
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 )
@@ -63,13 +64,37 @@ func (dfa *DeterministicFiniteAutomaton) makeMove() error {
 	}
 	val, ok := dfa.Transitions[key]
 	if !ok {
-		return fmt.Errorf("cannot continue calculations, missing transition for state %s and symbol %s", key.StateName, key.SymbolName)
+		return MissingTransitionError{StateName: key.StateName, SymbolName: key.SymbolName}
 	}
 	dfa.CurrentState = val.StateName
 	dfa.InputIt++
 	return nil
 }
 
+func (dfa DeterministicFiniteAutomaton) updateStatistics(stats *RunStatistics) {
+	// DFA doesn't have any specific statistics
+}
+
+func (dfa DeterministicFiniteAutomaton) record() moveRecord {
+	return moveRecord{stateName: dfa.CurrentState, it: dfa.InputIt}
+}
+
+func (dfa *DeterministicFiniteAutomaton) restore(r moveRecord) {
+	dfa.CurrentState = r.stateName
+	dfa.InputIt = r.it
+}
+
+func (dfa DeterministicFiniteAutomaton) storage() []string {
+	return nil
+}
+
+func (dfa DeterministicFiniteAutomaton) head() (string, string) {
+	if dfa.InputIt >= len(dfa.Input) {
+		return dfa.CurrentState, ""
+	}
+	return dfa.CurrentState, dfa.Input[dfa.InputIt]
+}
+
 func (dfa DeterministicFiniteAutomatonCurrentCalculationsState) SaveState(w io.Writer) error {
 	input := symbolsToString(dfa.InputLeft)
 	_, err := w.Write([]byte(fmt.Sprintf("current state: %s, input left: %s\n", dfa.State.Name, input)))
@@ -80,3 +105,29 @@ func (dfa DeterministicFiniteAutomatonResult) SaveResult(w io.Writer) error {
 	_, err := w.Write([]byte(fmt.Sprintf("final state: %s, accepted: %t\n", dfa.FinalState.Name, dfa.FinalState.Accepting)))
 	return err
 }
+
+func (dfa DeterministicFiniteAutomatonResult) Accepted() bool {
+	return dfa.FinalState.Accepting
+}
+
+func (dfa DeterministicFiniteAutomatonCurrentCalculationsState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State     string   `json:"state"`
+		Accepting bool     `json:"accepting"`
+		InputLeft []string `json:"input_left"`
+	}{
+		State:     dfa.State.Name,
+		Accepting: dfa.State.Accepting,
+		InputLeft: symbolsToNames(dfa.InputLeft),
+	})
+}
+
+func (dfa DeterministicFiniteAutomatonResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State    string `json:"state"`
+		Accepted bool   `json:"accepted"`
+	}{
+		State:    dfa.FinalState.Name,
+		Accepted: dfa.FinalState.Accepting,
+	})
+}
@@ -418,3 +418,40 @@ func TestSaveResultDFA(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWithIncludedCalculationsJSONDFA(t *testing.T) {
+	dfa := &DeterministicFiniteAutomaton{
+		States: map[string]State{
+			"qState":  {Name: "qState"},
+			"qState2": {Name: "qState2", Accepting: true},
+		},
+		CurrentState: "qState",
+		Symbols: map[string]Symbol{
+			"symbol1": {Name: "symbol1"},
+		},
+		Transitions: map[DFATransitionKey]DFATransitionValue{
+			{StateName: "qState", SymbolName: "symbol1"}: {StateName: "qState2"},
+		},
+		Input: []string{"symbol1"},
+	}
+	sb := &strings.Builder{}
+	opts := AutomatonOptions{
+		Output:              sb,
+		IncludeCalculations: true,
+		Format:              JSONFormat,
+	}
+	result, err := Run(context.Background(), dfa, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteResult(sb, result, JSONFormat); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"state":"qState","accepting":false,"input_left":["symbol1"]}
+{"state":"qState2","accepting":true,"input_left":[]}
+{"state":"qState2","accepted":true}
+`
+	if sb.String() != expected {
+		t.Errorf("invalid output, expected:\n%s, got:\n%s", expected, sb.String())
+	}
+}
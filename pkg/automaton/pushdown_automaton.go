@@ -1,7 +1,7 @@
 package automaton
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"io"
 )
@@ -77,7 +77,7 @@ func (pa PushdownAutomaton) result() AutomatonResult {
 
 func (pa *PushdownAutomaton) makeMove() error {
 	if len(pa.Stack) == 0 {
-		return errors.New("stack is empty")
+		return EmptyStackError{StateName: pa.CurrentState}
 	}
 	// Remove last element from stack
 	// It's user's responsibility to always have at least one element ('}') on the stack
@@ -94,7 +94,7 @@ func (pa *PushdownAutomaton) makeMove() error {
 
 	value, ok := pa.Transitions[key]
 	if !ok {
-		return fmt.Errorf("cannot continue calculations, missing transition for state %s, symbol %s and stack symbol %s", pa.CurrentState, input, stackSybmol)
+		return MissingTransitionError{StateName: pa.CurrentState, SymbolName: input, StackSymbolName: stackSybmol}
 	}
 
 	pa.CurrentState = value.StateName
@@ -103,6 +103,40 @@ func (pa *PushdownAutomaton) makeMove() error {
 	return nil
 }
 
+func (pa PushdownAutomaton) updateStatistics(stats *RunStatistics) {
+	stats.MaxStackDepth = max(stats.MaxStackDepth, len(pa.Stack))
+}
+
+func (pa PushdownAutomaton) record() moveRecord {
+	r := moveRecord{stateName: pa.CurrentState, it: pa.InputIt, length: len(pa.Stack)}
+	if len(pa.Stack) > 0 {
+		r.symbolName = pa.Stack[len(pa.Stack)-1]
+	}
+	return r
+}
+
+func (pa *PushdownAutomaton) restore(r moveRecord) {
+	pa.CurrentState = r.stateName
+	pa.InputIt = r.it
+	// Move pops only one symbol, everything below it is left untouched
+	if r.length > 0 {
+		pa.Stack = append(pa.Stack[:r.length-1], r.symbolName)
+	} else {
+		pa.Stack = pa.Stack[:0]
+	}
+}
+
+func (pa PushdownAutomaton) storage() []string {
+	return pa.Stack
+}
+
+func (pa PushdownAutomaton) head() (string, string) {
+	if pa.InputIt >= len(pa.Input) {
+		return pa.CurrentState, ""
+	}
+	return pa.CurrentState, pa.Input[pa.InputIt]
+}
+
 func (pa PushdownAutomaton) getStack() []Symbol {
 	stack := make([]Symbol, 0, len(pa.Stack))
 	for _, v := range pa.Stack {
@@ -132,3 +166,33 @@ func (pa PushdownAutomatonCurrentCalculationsState) SaveState(w io.Writer) error
 	_, err := w.Write([]byte(fmt.Sprintf("current state: %s, input left: %s, stack: %s\n", pa.CurrentState.Name, input, stack)))
 	return err
 }
+
+func (pa PushdownAutomatonResult) Accepted() bool {
+	return pa.FinalState.Accepting
+}
+
+func (pa PushdownAutomatonCurrentCalculationsState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State     string   `json:"state"`
+		Accepting bool     `json:"accepting"`
+		InputLeft []string `json:"input_left"`
+		Stack     []string `json:"stack"`
+	}{
+		State:     pa.CurrentState.Name,
+		Accepting: pa.CurrentState.Accepting,
+		InputLeft: symbolsToNames(pa.InputLeft),
+		Stack:     symbolsToNames(pa.Stack),
+	})
+}
+
+func (pa PushdownAutomatonResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State    string   `json:"state"`
+		Accepted bool     `json:"accepted"`
+		Stack    []string `json:"stack"`
+	}{
+		State:    pa.FinalState.Name,
+		Accepted: pa.FinalState.Accepting,
+		Stack:    symbolsToNames(pa.Stack),
+	})
+}
@@ -2,6 +2,7 @@ package automaton
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"strings"
 	"testing"
@@ -401,3 +402,65 @@ func TestSaveResultPA(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWithStatisticsPA(t *testing.T) {
+	pa := &PushdownAutomaton{
+		States: map[string]State{
+			"qState":  {Name: "qState"},
+			"qState2": {Name: "qState2", Accepting: true},
+		},
+		CurrentState: "qState",
+		Symbols: map[string]Symbol{
+			InputEndSymbol.Name:   InputEndSymbol,
+			StackStartSymbol.Name: StackStartSymbol,
+			"symbol1":             {Name: "symbol1"},
+		},
+		Transitions: map[PATransitionKey]PATransitionValue{
+			{StateName: "qState", InputSymbolName: "symbol1", StackSymbolName: StackStartSymbol.Name}: {StateName: "qState", StackSymbolNames: []string{StackStartSymbol.Name, "symbol1", "symbol1"}},
+			{StateName: "qState", InputSymbolName: "symbol1", StackSymbolName: "symbol1"}:             {StateName: "qState", StackSymbolNames: []string{}},
+			{StateName: "qState", InputSymbolName: InputEndSymbol.Name, StackSymbolName: "symbol1"}:   {StateName: "qState2", StackSymbolNames: []string{"symbol1"}},
+		},
+		Input: []string{"symbol1", "symbol1", InputEndSymbol.Name},
+		Stack: []string{StackStartSymbol.Name},
+	}
+	_, stats, err := RunWithStatistics(context.Background(), pa, AutomatonOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats.Elapsed = 0
+	expected := RunStatistics{Steps: 3, MaxStackDepth: 3}
+	if diff := cmp.Diff(expected, stats); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMarshalJSONPA(t *testing.T) {
+	state := PushdownAutomatonCurrentCalculationsState{
+		CurrentState: State{Name: "qState"},
+		Stack:        []Symbol{StackStartSymbol, {Name: "s1"}},
+		InputLeft:    []Symbol{{Name: "s2"}, InputEndSymbol},
+	}
+	result := PushdownAutomatonResult{
+		FinalState: State{Name: "qState", Accepting: true},
+		Stack:      []Symbol{},
+	}
+	data := []struct {
+		name     string
+		value    json.Marshaler
+		expected string
+	}{
+		{"calculations state", state, `{"state":"qState","accepting":false,"input_left":["s2","{"],"stack":["}","s1"]}`},
+		{"result", result, `{"state":"qState","accepted":true,"stack":[]}`},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := json.Marshal(d.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != d.expected {
+				t.Errorf("invalid json, expected: %s, got: %s", d.expected, string(b))
+			}
+		})
+	}
+}
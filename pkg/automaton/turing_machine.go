@@ -1,7 +1,7 @@
 package automaton
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -42,6 +42,8 @@ type TuringMachine struct {
 type TuringMachineResult struct {
 	FinalState State
 	FinalTape  []Symbol
+	// Head is the final position of the head, it can point past `FinalTape` as trailing blanks are removed from it
+	Head int
 }
 
 type TuringMachineCurrentCalculationsState struct {
@@ -63,7 +65,7 @@ func (tm TuringMachine) calculationsFinished() bool {
 func (tm TuringMachine) result() AutomatonResult {
 	finalState := tm.States[tm.CurrentState]
 	finalTape := removeUnnecessaryBlanks(tm.getTape())
-	return TuringMachineResult{FinalState: finalState, FinalTape: finalTape}
+	return TuringMachineResult{FinalState: finalState, FinalTape: finalTape, Head: tm.TapeIt}
 }
 
 func (tm *TuringMachine) makeMove() error {
@@ -73,14 +75,14 @@ func (tm *TuringMachine) makeMove() error {
 	}
 	val, ok := tm.Transitions[key]
 	if !ok {
-		return fmt.Errorf("cannot continue calculations, missing transition for state %s and symbol %s", key.StateName, key.SymbolName)
+		return MissingTransitionError{StateName: key.StateName, SymbolName: key.SymbolName}
 	}
 	tm.Tape[tm.TapeIt] = val.SymbolName
 	tm.CurrentState = val.StateName
 	if val.Move == TapeMoveLeft {
 		tm.TapeIt--
 		if tm.TapeIt < 0 {
-			return errors.New("cannot continue calculations, turing machine went out of tape")
+			return TapeUnderflowError{StateName: key.StateName, SymbolName: key.SymbolName}
 		}
 	} else {
 		tm.TapeIt++
@@ -91,6 +93,38 @@ func (tm *TuringMachine) makeMove() error {
 	return nil
 }
 
+func (tm TuringMachine) updateStatistics(stats *RunStatistics) {
+	// Tape is extended only when head moves right past its end, so its length is the number of used cells
+	stats.TapeCellsUsed = max(stats.TapeCellsUsed, len(tm.Tape))
+	// Statistics are updated also before the first move, every move moves the head by one cell
+	if stats.Steps > 0 {
+		stats.HeadTravel++
+	}
+}
+
+func (tm TuringMachine) record() moveRecord {
+	return moveRecord{stateName: tm.CurrentState, it: tm.TapeIt, symbolName: tm.Tape[tm.TapeIt], length: len(tm.Tape)}
+}
+
+func (tm *TuringMachine) restore(r moveRecord) {
+	tm.CurrentState = r.stateName
+	// Tape is extended by at most one blank cell in every move
+	tm.Tape = tm.Tape[:r.length]
+	tm.TapeIt = r.it
+	tm.Tape[tm.TapeIt] = r.symbolName
+}
+
+func (tm TuringMachine) storage() []string {
+	return tm.Tape
+}
+
+func (tm TuringMachine) head() (string, string) {
+	if tm.TapeIt >= len(tm.Tape) {
+		return tm.CurrentState, ""
+	}
+	return tm.CurrentState, tm.Tape[tm.TapeIt]
+}
+
 // removeUnnecessaryBlanks removes blank symbols starting from the end of the tape until there is at most one
 // blank symbol in the row at the end
 func removeUnnecessaryBlanks(tape []Symbol) []Symbol {
@@ -139,3 +173,35 @@ func (tmr TuringMachineResult) SaveResult(w io.Writer) error {
 	_, err := w.Write([]byte(fmt.Sprintf("final state: %s, tape: %s\n", tmr.FinalState.Name, tape)))
 	return err
 }
+
+func (tmr TuringMachineResult) Accepted() bool {
+	return tmr.FinalState.Accepting
+}
+
+func (tmc TuringMachineCurrentCalculationsState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State     string   `json:"state"`
+		Accepting bool     `json:"accepting"`
+		Tape      []string `json:"tape"`
+		Head      int      `json:"head"`
+	}{
+		State:     tmc.State.Name,
+		Accepting: tmc.State.Accepting,
+		Tape:      symbolsToNames(tmc.Tape),
+		Head:      tmc.It,
+	})
+}
+
+func (tmr TuringMachineResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		State    string   `json:"state"`
+		Accepted bool     `json:"accepted"`
+		Tape     []string `json:"tape"`
+		Head     int      `json:"head"`
+	}{
+		State:    tmr.FinalState.Name,
+		Accepted: tmr.FinalState.Accepting,
+		Tape:     symbolsToNames(tmr.FinalTape),
+		Head:     tmr.Head,
+	})
+}
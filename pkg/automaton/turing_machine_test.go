@@ -2,6 +2,8 @@ package automaton
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"strings"
@@ -82,6 +84,7 @@ func TestRunTM(t *testing.T) {
 					{Name: "symbol2"},
 					{Name: "symbol1"},
 				},
+				Head: 2,
 			},
 			"",
 		},
@@ -113,6 +116,7 @@ func TestRunTM(t *testing.T) {
 				FinalTape: []Symbol{
 					BlankSymbol,
 				},
+				Head: 2,
 			},
 			"",
 		},
@@ -163,7 +167,7 @@ func TestRunTM(t *testing.T) {
 			"cannot continue calculations, turing machine went out of tape",
 		},
 		{
-			"infinite loop with timeout",
+			"never ending calculations with timeout",
 			&TuringMachine{
 				States: map[string]State{
 					"q0": {Name: "q0"},
@@ -175,18 +179,98 @@ func TestRunTM(t *testing.T) {
 				},
 				Transitions: map[TMTransitionKey]TMTransitionValue{
 					{StateName: "q0", SymbolName: BlankSymbol.Name}: {StateName: "q1", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
-					{StateName: "q1", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveLeft},
+					{StateName: "q1", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
 				},
 				Tape: []string{
 					BlankSymbol.Name,
 				},
 				TapeIt: 0,
 			},
-			500,
+			10,
 			AutomatonOptions{Output: io.Discard},
 			zero,
 			"timeout reached",
 		},
+		{
+			"never ending calculations with step limit",
+			&TuringMachine{
+				States: map[string]State{
+					"q0": {Name: "q0"},
+					"q1": {Name: "q1"},
+				},
+				CurrentState: "q0",
+				Symbols: map[string]Symbol{
+					BlankSymbol.Name: BlankSymbol,
+				},
+				Transitions: map[TMTransitionKey]TMTransitionValue{
+					{StateName: "q0", SymbolName: BlankSymbol.Name}: {StateName: "q1", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
+					{StateName: "q1", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
+				},
+				Tape: []string{
+					BlankSymbol.Name,
+				},
+				TapeIt: 0,
+			},
+			0,
+			AutomatonOptions{Output: io.Discard, MaxSteps: 3},
+			zero,
+			"step limit exceeded after 3 steps, last configuration:\ncurrent state: q1, tape: B|B|B|B\n                               ^",
+		},
+		{
+			"infinite loop",
+			&TuringMachine{
+				States: map[string]State{
+					"q0": {Name: "q0"},
+					"q1": {Name: "q1"},
+				},
+				CurrentState: "q0",
+				Symbols: map[string]Symbol{
+					BlankSymbol.Name: BlankSymbol,
+				},
+				Transitions: map[TMTransitionKey]TMTransitionValue{
+					{StateName: "q0", SymbolName: BlankSymbol.Name}: {StateName: "q1", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
+					{StateName: "q1", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveLeft},
+				},
+				Tape: []string{
+					BlankSymbol.Name,
+				},
+				TapeIt: 0,
+			},
+			0,
+			AutomatonOptions{Output: io.Discard},
+			zero,
+			"infinite loop detected at step 3, repeats every 2 steps",
+		},
+		{
+			"infinite loop writing on tape",
+			&TuringMachine{
+				States: map[string]State{
+					"q0": {Name: "q0"},
+					"q1": {Name: "q1"},
+					"q2": {Name: "q2"},
+					"q3": {Name: "q3"},
+				},
+				CurrentState: "q0",
+				Symbols: map[string]Symbol{
+					BlankSymbol.Name: BlankSymbol,
+					"1":              {Name: "1"},
+				},
+				Transitions: map[TMTransitionKey]TMTransitionValue{
+					{StateName: "q0", SymbolName: BlankSymbol.Name}: {StateName: "q1", SymbolName: "1", Move: TapeMoveRight},
+					{StateName: "q1", SymbolName: BlankSymbol.Name}: {StateName: "q2", SymbolName: BlankSymbol.Name, Move: TapeMoveLeft},
+					{StateName: "q2", SymbolName: "1"}:              {StateName: "q3", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
+					{StateName: "q3", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveLeft},
+				},
+				Tape: []string{
+					BlankSymbol.Name,
+				},
+				TapeIt: 0,
+			},
+			0,
+			AutomatonOptions{Output: io.Discard},
+			zero,
+			"infinite loop detected at step 7, repeats every 4 steps",
+		},
 	}
 	for _, d := range data {
 		t.Run(d.name, func(t *testing.T) {
@@ -242,6 +326,7 @@ func TestRunWithIncludedCalculationsTM(t *testing.T) {
 		FinalTape: []Symbol{
 			{Name: BlankSymbol.Name},
 		},
+		Head: 1,
 	}
 	expectedCalculations := "current state: qState, tape: B\n"
 	l := len(expectedCalculations)
@@ -364,3 +449,115 @@ func TestSaveResultTM(t *testing.T) {
 		t.Errorf("invalid result string, expected:\n%s, got:\n%s", expected, result.String())
 	}
 }
+
+func TestRunStepLimitExceededErrorTM(t *testing.T) {
+	tm := &TuringMachine{
+		States: map[string]State{
+			"q0": {Name: "q0"},
+		},
+		CurrentState: "q0",
+		Symbols: map[string]Symbol{
+			BlankSymbol.Name: BlankSymbol,
+		},
+		Transitions: map[TMTransitionKey]TMTransitionValue{
+			{StateName: "q0", SymbolName: BlankSymbol.Name}: {StateName: "q0", SymbolName: BlankSymbol.Name, Move: TapeMoveRight},
+		},
+		Tape: []string{
+			BlankSymbol.Name,
+		},
+		TapeIt: 0,
+	}
+	_, err := Run(context.Background(), tm, AutomatonOptions{MaxSteps: 10})
+	var slErr StepLimitExceededError
+	if !errors.As(err, &slErr) {
+		t.Fatalf("invalid error type, expected: StepLimitExceededError, got: %v", err)
+	}
+	if slErr.Steps != 10 {
+		t.Errorf("invalid steps, expected: %d, got: %d", 10, slErr.Steps)
+	}
+	expectedConfiguration := TuringMachineCurrentCalculationsState{
+		State: State{Name: "q0"},
+		Tape:  []Symbol{BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol, BlankSymbol},
+		It:    10,
+	}
+	if diff := cmp.Diff(expectedConfiguration, slErr.Configuration); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRunWithStatisticsTM(t *testing.T) {
+	data := []struct {
+		name     string
+		maxSteps int
+		expected RunStatistics
+	}{
+		// Head turns back at symbol2, so it travels by 4 cells while the last cell is never visited
+		{"finished run", 0, RunStatistics{Steps: 4, TapeCellsUsed: 4, HeadTravel: 4}},
+		{"run stopped by step limit", 2, RunStatistics{Steps: 2, TapeCellsUsed: 4, HeadTravel: 2}},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			tm := &TuringMachine{
+				States: map[string]State{
+					"qRight": {Name: "qRight"},
+					"qLeft":  {Name: "qLeft"},
+					"qAcc":   {Name: "qAcc", Accepting: true},
+				},
+				CurrentState: "qRight",
+				Symbols: map[string]Symbol{
+					BlankSymbol.Name: BlankSymbol,
+					"symbol1":        {Name: "symbol1"},
+					"symbol2":        {Name: "symbol2"},
+				},
+				Transitions: map[TMTransitionKey]TMTransitionValue{
+					{StateName: "qRight", SymbolName: "symbol1"}: {StateName: "qRight", SymbolName: "symbol1", Move: TapeMoveRight},
+					{StateName: "qRight", SymbolName: "symbol2"}: {StateName: "qLeft", SymbolName: "symbol2", Move: TapeMoveLeft},
+					{StateName: "qLeft", SymbolName: "symbol1"}:  {StateName: "qAcc", SymbolName: "symbol1", Move: TapeMoveLeft},
+				},
+				Tape:   []string{"symbol1", "symbol1", "symbol2", "symbol1"},
+				TapeIt: 0,
+			}
+			_, stats, err := RunWithStatistics(context.Background(), tm, AutomatonOptions{MaxSteps: d.maxSteps})
+			var stepLimitErr StepLimitExceededError
+			if err != nil && !errors.As(err, &stepLimitErr) {
+				t.Fatal(err)
+			}
+			stats.Elapsed = 0
+			if diff := cmp.Diff(d.expected, stats); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONTM(t *testing.T) {
+	state := TuringMachineCurrentCalculationsState{
+		State: State{Name: "qState"},
+		Tape:  []Symbol{{Name: "s1"}, BlankSymbol},
+		It:    1,
+	}
+	result := TuringMachineResult{
+		FinalState: State{Name: "qState", Accepting: true},
+		FinalTape:  []Symbol{{Name: "s1"}, BlankSymbol},
+		Head:       1,
+	}
+	data := []struct {
+		name     string
+		value    json.Marshaler
+		expected string
+	}{
+		{"calculations state", state, `{"state":"qState","accepting":false,"tape":["s1","B"],"head":1}`},
+		{"result", result, `{"state":"qState","accepted":true,"tape":["s1","B"],"head":1}`},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := json.Marshal(d.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != d.expected {
+				t.Errorf("invalid json, expected: %s, got: %s", d.expected, string(b))
+			}
+		})
+	}
+}
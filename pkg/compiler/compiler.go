@@ -5,28 +5,195 @@ import (
 	"automata-compiler/pkg/lexer"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 )
 
 type Compiler interface {
 	Compile() (automaton.Automaton, error)
+	// CompileInput validates input provided separately from the source (e.g. from command line) and returns
+	// automaton that will process it, it must be called after successful `Compile`
+	CompileInput(tokens []lexer.Token) (automaton.Automaton, error)
+	// CompileInputFromReader works like `CompileInput`, but tokens are read one by one from `r`
+	CompileInputFromReader(r lexer.TokenReader) (automaton.Automaton, error)
+	// Tests returns test cases from the tests section, it should be called after `Compile`
+	Tests() []TestCase
+	// SourceLines returns lines where states, symbols and transitions are declared, it should be called
+	// after `Compile`
+	SourceLines() SourceLines
+}
+
+// SourceLines maps elements of the automaton to lines of the source where they are declared, so problems
+// found in the compiled automaton can be reported with line numbers
+type SourceLines struct {
+	States  map[string]int
+	Symbols map[string]int
+	// Transitions maps transition function key (e.g. `automaton.TMTransitionKey`) to line of the transition
+	Transitions map[any]int
 }
 
 // BaseCompiler implements simple utility functions that every automaton compiler needs
 type BaseCompiler struct {
+	// tokens are all tokens of the source, or for compilers reading from `reader` only the last few of them
 	tokens []lexer.Token
 	it     int
+	// reader is the source of tokens for compilers created from `lexer.TokenReader`, nil otherwise
+	reader lexer.TokenReader
+	// readDone is set when EOF token or an error is read from `reader`
+	readDone bool
+	// lexErr is the error returned by `reader`, compilation is stopped by it
+	lexErr error
+	// errsBeforeLexErr is the number of errors found before `lexErr`, the following ones are only consequences
+	// of the incomplete source
+	errsBeforeLexErr int
+	tests            []TestCase
+	// symbols declared in the source, set during `Compile`
+	symbols map[string]automaton.Symbol
+	// newAutomaton creates automaton defined in the source with provided input, set during `Compile`
+	newAutomaton func(input []string) automaton.Automaton
+	lines        SourceLines
+	// errs are errors found so far, compilation continues after an error so all of them are reported at once
+	errs []error
+	// stopped is set when recovery from an error reached the end of the source, any further errors would be
+	// only consequences of the earlier ones
+	stopped bool
+	// reserved are types of reserved letters (`reservedLetters`) that have special meaning for the automaton,
+	// other reserved letters are ordinary symbols
+	reserved []lexer.TokenType
+	// ranks maps transition function key to rank of the transition that defined it, see `defineTransition`
+	ranks map[any]int
+	// special are names of special symbols of the automaton, e.g. `B` of TM. Quoted symbol with such name
+	// (e.g. `'B'`) would be the special symbol, so it's reported as an error.
+	special []string
+	// reportedSpecial are positions of the quoted special symbols already reported, the same token can be read
+	// again after error recovery or used many times by macros
+	reportedSpecial map[lexer.Span]bool
+}
+
+// reservedLetters are token types of single letters that are reserved only in some automata, e.g. `L` is
+// a move of TM, but it's an ordinary symbol in DFA
+var reservedLetters = []lexer.TokenType{lexer.BlankSymbolToken, lexer.MoveLeftToken, lexer.MoveRightToken}
+
+// CompileError is an error found at the given token of the source
+type CompileError struct {
+	// Line is 0 if the error isn't related to any part of the source, e.g. a missing section
+	Line int
+	// Column is 0 if unknown
+	Column int
+	// Span is the fragment of the source occupied by the token
+	Span lexer.Span
+	Err  error
+}
+
+func newCompileError(err error, t lexer.Token) CompileError {
+	return CompileError{Line: t.Line, Column: t.Column, Span: t.Span, Err: err}
+}
+
+func (e CompileError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("[Line %d] %s", e.Line, e.Err.Error())
+	}
+	return fmt.Sprintf("[Line %d, Column %d] %s", e.Line, e.Column, e.Err.Error())
+}
+
+func (e CompileError) Unwrap() error {
+	return e.Err
+}
+
+// Errors holds all errors found during compilation, in order in which they were found
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
 }
 
-func newBaseCompiler(tokens []lexer.Token) BaseCompiler {
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// newBaseCompiler creates compiler of `tokens`, `special` are names of special symbols of the automaton and
+// `reserved` are types of reserved letters used by it
+func newBaseCompiler(tokens []lexer.Token, special []string, reserved ...lexer.TokenType) BaseCompiler {
 	return BaseCompiler{
 		tokens: tokens,
 		it:     0,
+		lines: SourceLines{
+			States:      make(map[string]int),
+			Symbols:     make(map[string]int),
+			Transitions: make(map[any]int),
+		},
+		reserved:        reserved,
+		ranks:           make(map[any]int),
+		special:         special,
+		reportedSpecial: make(map[lexer.Span]bool),
 	}
 }
 
-func (c BaseCompiler) isAtEnd() bool {
-	return c.it >= len(c.tokens)
+// newBaseCompilerFromReader creates compiler reading tokens from `r` when they are needed, so the whole source
+// is held in memory only if it uses named sections, see `processSections`
+func newBaseCompilerFromReader(r lexer.TokenReader, special []string, reserved ...lexer.TokenType) BaseCompiler {
+	c := newBaseCompiler(make([]lexer.Token, 0), special, reserved...)
+	c.reader = r
+	return c
+}
+
+func (c BaseCompiler) Tests() []TestCase {
+	return c.tests
+}
+
+func (c BaseCompiler) SourceLines() SourceLines {
+	return c.lines
+}
+
+// checkCompiled returns an error if `Compile` hasn't finished successfully yet
+func (c BaseCompiler) checkCompiled() error {
+	if c.newAutomaton == nil {
+		return errors.New("source must be compiled before compiling input")
+	}
+	return nil
+}
+
+// isInputOmitted reports whether source doesn't contain input section, in which case input is expected
+// to be provided separately with `CompileInput`
+func (c *BaseCompiler) isInputOmitted() bool {
+	t := c.peek().Type
+	return t == lexer.TestsToken || t == lexer.EOFToken
+}
+
+func (c *BaseCompiler) isAtEnd() bool {
+	return c.it >= len(c.tokens) && !c.fill()
+}
+
+// fill reads the next token from `reader`, it returns false if there are no more tokens. Only two tokens before
+// the current one are kept: the previous one is needed for error positions, the one before it for error recovery.
+func (c *BaseCompiler) fill() bool {
+	if c.reader == nil || c.readDone {
+		return false
+	}
+	t, err := c.reader.Next()
+	if err != nil {
+		c.lexErr = err
+		c.errsBeforeLexErr = len(c.errs)
+		c.readDone = true
+		return false
+	}
+	if t.Type == lexer.EOFToken {
+		c.readDone = true
+	}
+	if c.it > 2 {
+		n := copy(c.tokens, c.tokens[c.it-2:])
+		c.tokens = c.tokens[:n]
+		c.it = 2
+	}
+	c.tokens = append(c.tokens, t)
+	return true
 }
 
 func (c *BaseCompiler) advance() lexer.Token {
@@ -34,18 +201,36 @@ func (c *BaseCompiler) advance() lexer.Token {
 		var t lexer.Token
 		return t
 	}
-	t := c.tokens[c.it]
+	t := c.resolveReserved(c.tokens[c.it])
 	c.it++
+	if c.isQuotedSpecial(t) && !c.reportedSpecial[t.Span] {
+		c.reportedSpecial[t.Span] = true
+		c.report(fmt.Errorf("quoted symbol %s has the same name as a special symbol of the automaton", t.Value))
+	}
 	return t
 }
 
 // peek is same as `advance` but doesn't move the `it`
-func (c BaseCompiler) peek() lexer.Token {
+func (c *BaseCompiler) peek() lexer.Token {
 	if c.isAtEnd() {
 		var t lexer.Token
 		return t
 	}
-	return c.tokens[c.it]
+	return c.resolveReserved(c.tokens[c.it])
+}
+
+// isQuotedSpecial reports whether `t` is a quoted symbol with the name of a special symbol, e.g. `'B'` in TM. Names
+// of special symbols are never lexed as symbols unless they are quoted.
+func (c BaseCompiler) isQuotedSpecial(t lexer.Token) bool {
+	return t.Type == lexer.SymbolToken && slices.Contains(c.special, t.Value)
+}
+
+// resolveReserved turns reserved letter without special meaning for the automaton into a symbol
+func (c BaseCompiler) resolveReserved(t lexer.Token) lexer.Token {
+	if slices.Contains(reservedLetters, t.Type) && !slices.Contains(c.reserved, t.Type) {
+		t.Type = lexer.SymbolToken
+	}
+	return t
 }
 
 func (c *BaseCompiler) consumeTokenWithType(atEndErrMsg string, expected ...lexer.TokenType) (lexer.Token, error) {
@@ -60,15 +245,86 @@ func (c *BaseCompiler) consumeTokenWithType(atEndErrMsg string, expected ...lexe
 	return token, nil
 }
 
-func (c BaseCompiler) prevTokenLine() int {
+func (c BaseCompiler) addLinePrefixForErrPrevToken(err error) error {
 	if c.it == 0 {
-		return 0
+		return CompileError{Err: err}
 	}
-	return c.tokens[c.it-1].Line
+	return newCompileError(err, c.tokens[c.it-1])
 }
 
-func (c BaseCompiler) addLinePrefixForErrPrevToken(err error) error {
-	return addLinePrefixForErr(err, c.prevTokenLine())
+// report records error found at the previous token, errors found after recovery reached the end of the source
+// are ignored
+func (c *BaseCompiler) report(err error) {
+	if c.stopped {
+		return
+	}
+	c.errs = append(c.errs, c.addLinePrefixForErrPrevToken(err))
+}
+
+// recoverSection reports error found in one of the sections ending with ';' and skips all tokens until
+// the end of the section, so the next section can be processed
+func (c *BaseCompiler) recoverSection(err error) {
+	if err == nil {
+		return
+	}
+	c.report(err)
+	if c.it == 0 || c.tokens[c.it-1].Type != lexer.SemicolonToken {
+		for !c.isAtEnd() && c.peek().Type != lexer.EOFToken {
+			if c.advance().Type == lexer.SemicolonToken {
+				break
+			}
+		}
+	}
+	// Nothing left to process, errors of the following sections would only repeat that the source is unfinished
+	if c.isAtEnd() || c.peek().Type == lexer.EOFToken {
+		c.stopped = true
+	}
+}
+
+// recoverItem reports error found in a single transition or test case and skips tokens until the start
+// of the next one or the end of the section, the token that caused the error is included in the search
+func (c *BaseCompiler) recoverItem(err error) {
+	c.report(err)
+	if c.it > 0 && (c.tokens[c.it-1].Type == lexer.SemicolonToken || c.isItemStart(c.it-1)) {
+		c.it--
+		return
+	}
+	for !c.isAtEnd() && c.peek().Type != lexer.EOFToken {
+		if c.peek().Type == lexer.SemicolonToken || c.isItemStart(c.it) {
+			return
+		}
+		c.advance()
+	}
+	c.stopped = true
+}
+
+// isItemStart reports whether token with index `i` starts transition or test case, that is it's '(' not
+// preceded by '>' (which starts the right side)
+func (c BaseCompiler) isItemStart(i int) bool {
+	return c.tokens[i].Type == lexer.LeftParenToken && (i == 0 || c.tokens[i-1].Type != lexer.ArrowToken)
+}
+
+// finish checks the end of the source and returns all errors found during compilation, `Errors` is returned
+// only if there is more than one error. Lexing error ends the errors, as errors found after it are only
+// consequences of the incomplete source.
+func (c *BaseCompiler) finish() error {
+	if c.lexErr != nil {
+		c.errs = append(c.errs[:c.errsBeforeLexErr], c.lexErr)
+	} else if !c.stopped {
+		if err := c.checkForCorrectEndingSequnce(); err != nil {
+			// It's more lexer error than user provided source,
+			// so we don't include line here
+			c.errs = append(c.errs, CompileError{Err: err})
+		}
+	}
+	switch len(c.errs) {
+	case 0:
+		return nil
+	case 1:
+		return c.errs[0]
+	default:
+		return Errors(c.errs)
+	}
 }
 
 func (c *BaseCompiler) checkForCorrectEndingSequnce() error {
@@ -81,6 +337,112 @@ func (c *BaseCompiler) checkForCorrectEndingSequnce() error {
 	return nil
 }
 
+// automatonTypes are the types of automata that can be declared in the header
+var automatonTypes = []string{"DFA", "PA", "TM"}
+
+// DeclaredType returns automaton type declared in the header of the source read from `r`, e.g. "TM" for
+// `type TM;`, or an empty string if the source doesn't start with the header
+func DeclaredType(r lexer.TokenReader) string {
+	if t, err := r.Next(); err != nil || t.Type != lexer.TypeToken {
+		return ""
+	}
+	t, err := r.Next()
+	if err != nil || t.Type != lexer.SymbolToken {
+		return ""
+	}
+	return t.Value
+}
+
+// processHeader processes optional header declaring type of the automaton, e.g. `type TM;`, `aType` is the type
+// of automaton compiled by the compiler. Error in the header stops compilation, as sections of automaton of
+// another type would only produce confusing errors.
+func (c *BaseCompiler) processHeader(aType string) {
+	if err := c.checkHeader(aType); err != nil {
+		c.recoverSection(err)
+		c.stopped = true
+	}
+}
+
+// checkHeader returns an error if the header is invalid or declares type other than `aType`
+func (c *BaseCompiler) checkHeader(aType string) error {
+	if c.peek().Type != lexer.TypeToken {
+		return nil
+	}
+	// Consume type keyword
+	c.advance()
+	t, err := c.consumeTokenWithType("missing automaton type after 'type'", lexer.SymbolToken)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(automatonTypes, func(name string) bool { return strings.EqualFold(name, t.Value) }) {
+		return fmt.Errorf("unknown automaton type %s, expected one of: %s", t.Value, strings.Join(automatonTypes, ", "))
+	}
+	if !strings.EqualFold(t.Value, aType) {
+		return fmt.Errorf("source declares automaton type %s, but it's compiled as %s", t.Value, aType)
+	}
+	if c.isAtEnd() || c.advance().Type != lexer.SemicolonToken {
+		return errors.New("missing ';' after automaton type")
+	}
+	return nil
+}
+
+// sectionOrder is the order of sections in the positional syntax, sections given with names are reordered to it
+var sectionOrder = []string{"states", "initial", "accepting", "symbols", "transitions", "input"}
+
+// processSections processes sections given with names (e.g. `accepting: qA;`) in any order, tokens of the source
+// are replaced by tokens of the sections in the positional order, so they are processed the same way as sections
+// without names. All the sections, except for the optional input, must be given. Sections are read before they
+// are processed, so the whole source is held in memory even if tokens are read from `reader`.
+func (c *BaseCompiler) processSections() {
+	if c.peek().Type != lexer.SectionToken {
+		return
+	}
+	sections := make(map[string][]lexer.Token)
+	for !c.isAtEnd() && c.peek().Type != lexer.TestsToken && c.peek().Type != lexer.EOFToken {
+		t := c.advance()
+		if t.Type != lexer.SectionToken {
+			// Sections without names can't follow named ones
+			c.recoverSection(lexer.UnexpectedTokenErr(t, lexer.SectionToken, lexer.TestsToken, lexer.EOFToken))
+			continue
+		}
+		name := t.Value
+		if !slices.Contains(sectionOrder, name) {
+			c.recoverSection(fmt.Errorf("unknown section %s, expected one of: %s", name, strings.Join(sectionOrder, ", ")))
+			continue
+		}
+		if _, ok := sections[name]; ok {
+			c.recoverSection(fmt.Errorf("duplicate %s section", name))
+			continue
+		}
+		section := make([]lexer.Token, 0)
+		for !c.isAtEnd() && c.peek().Type != lexer.EOFToken {
+			t := c.advance()
+			section = append(section, t)
+			if t.Type == lexer.SemicolonToken {
+				break
+			}
+		}
+		sections[name] = section
+	}
+	tokens := make([]lexer.Token, 0)
+	missing := false
+	for _, name := range sectionOrder {
+		section, ok := sections[name]
+		if !ok && name != "input" {
+			// Processing sections without the missing one would only report its consequences
+			c.errs = append(c.errs, CompileError{Err: fmt.Errorf("%s section missing", name)})
+			missing = true
+		}
+		tokens = append(tokens, section...)
+	}
+	// Tests section and EOF are not named
+	for !c.isAtEnd() {
+		tokens = append(tokens, c.advance())
+	}
+	c.tokens, c.it, c.reader = tokens, 0, nil
+	c.stopped = c.stopped || missing
+}
+
 func (c *BaseCompiler) processStates() (map[string]automaton.State, error) {
 	states := make(map[string]automaton.State)
 	for !c.isAtEnd() {
@@ -88,20 +450,21 @@ func (c *BaseCompiler) processStates() (map[string]automaton.State, error) {
 		switch t.Type {
 		case lexer.SemicolonToken:
 			if len(states) == 0 {
-				return nil, errors.New("there must be at least one state defined")
+				return states, errors.New("there must be at least one state defined")
 			}
 			return states, nil
 		case lexer.StateToken:
 			name := t.Value
 			if _, ok := states[name]; ok {
-				return nil, fmt.Errorf("state %s already declared, each state must have unique name", name)
+				return states, fmt.Errorf("state %s already declared, each state must have unique name", name)
 			}
 			states[name] = automaton.State{Name: name, Accepting: false}
+			c.lines.States[name] = t.Line
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.StateToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			return states, lexer.UnexpectedTokenErr(t, lexer.StateToken, lexer.SemicolonToken)
 		}
 	}
-	return nil, errors.New("missing ';' at the end of states section")
+	return states, errors.New("missing ';' at the end of states section")
 }
 
 func (c *BaseCompiler) processInitialState(states map[string]automaton.State) (string, error) {
@@ -131,7 +494,7 @@ func (c *BaseCompiler) processAcceptingStates(states map[string]automaton.State)
 			}
 			states[name] = automaton.State{Name: name, Accepting: true}
 		default:
-			return fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.StateToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			return lexer.UnexpectedTokenErr(t, lexer.StateToken, lexer.SemicolonToken)
 		}
 	}
 	return errors.New("missing ';' at the end of accepting states section")
@@ -145,21 +508,185 @@ func (c *BaseCompiler) processSymbols(symbols map[string]automaton.Symbol) (map[
 		case lexer.SemicolonToken:
 			return symbols, nil
 		case lexer.SymbolToken:
+			if c.isQuotedSpecial(t) {
+				// Already reported by `advance`
+				continue
+			}
 			name := t.Value
 			if _, ok := symbols[name]; ok {
-				return nil, fmt.Errorf("symbol %s already declared, each symbol must have unique name", name)
+				return symbols, fmt.Errorf("symbol %s already declared, each symbol must have unique name", name)
 			}
 			symbols[name] = automaton.Symbol{Name: t.Value}
+			c.lines.Symbols[name] = t.Line
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.SymbolToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			return symbols, lexer.UnexpectedTokenErr(t, lexer.SymbolToken, lexer.SemicolonToken)
 		}
 	}
-	return nil, errors.New("missing ';' at the end of symbols section")
+	return symbols, errors.New("missing ';' at the end of symbols section")
 }
 
-// addLinePrefixForErr modifies provided error message, adding prefix which contains line number
-func addLinePrefixForErr(err error, line int) error {
-	return fmt.Errorf("[Line %d] %s", line, err.Error())
+// Ranks of symbol patterns used in the transition left side. If many transitions match the same symbols, the one
+// with the lowest rank (sum of ranks of its patterns) is used, so explicit symbols take precedence over classes
+// and classes over wildcards.
+const (
+	symbolRank = iota
+	symbolClassRank
+	wildcardRank
+)
+
+// symbolPattern is a part of the transition left side matching one or more symbols: a single symbol, a class
+// of symbols (e.g. `{0, 1}`) or wildcard `*`
+type symbolPattern struct {
+	symbols []string
+	rank    int
+}
+
+// processSymbolPattern processes symbol pattern, `wildcard` are symbols matched by `*` and `allowed` are types of
+// tokens that can be used as symbols. Symbols of the pattern must be checked against declared symbols by the caller.
+func (c *BaseCompiler) processSymbolPattern(wildcard []string, atEndErrMsg string, allowed ...lexer.TokenType) (symbolPattern, error) {
+	if c.peek().Type == lexer.WildcardToken {
+		c.advance()
+		return symbolPattern{symbols: wildcard, rank: wildcardRank}, nil
+	}
+	if c.peek().Type == lexer.ClassStartToken {
+		return c.processSymbolClass(atEndErrMsg, allowed...)
+	}
+	t, err := c.consumeTokenWithType(atEndErrMsg, allowed...)
+	if err != nil {
+		return symbolPattern{}, err
+	}
+	return symbolPattern{symbols: []string{t.Value}, rank: symbolRank}, nil
+}
+
+// processSymbolClass processes class of symbols, e.g. `{0, 1}`, special symbols of PA can't be used in classes
+func (c *BaseCompiler) processSymbolClass(atEndErrMsg string, allowed ...lexer.TokenType) (symbolPattern, error) {
+	var zero symbolPattern
+	// Consume '{'
+	c.advance()
+	allowed = slices.DeleteFunc(slices.Clone(allowed), func(tt lexer.TokenType) bool {
+		return tt == lexer.InputEndToken || tt == lexer.StackStartToken
+	})
+	symbols := make([]string, 0)
+	for {
+		t, err := c.consumeTokenWithType(atEndErrMsg, allowed...)
+		if err != nil {
+			return zero, err
+		}
+		if slices.Contains(symbols, t.Value) {
+			return zero, fmt.Errorf("symbol %s used more than once in symbol class", t.Value)
+		}
+		symbols = append(symbols, t.Value)
+		if c.isAtEnd() {
+			return zero, errors.New(atEndErrMsg)
+		}
+		switch t := c.advance(); t.Type {
+		case lexer.CommaToken:
+		case lexer.ClassEndToken:
+			return symbolPattern{symbols: symbols, rank: symbolClassRank}, nil
+		default:
+			return zero, fmt.Errorf("expected ',' or '}' in symbol class, got %s", t.Type.Description())
+		}
+	}
+}
+
+// wildcardSymbols returns sorted names of `symbols` matched by `*`, except for `excluded`
+func wildcardSymbols(symbols map[string]automaton.Symbol, excluded ...string) []string {
+	names := make([]string, 0, len(symbols))
+	for name := range symbols {
+		if !slices.Contains(excluded, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// duplicateTransition returns line of the transition defined earlier for `key` with the same rank, transitions
+// with different ranks don't conflict as one of them takes precedence
+func (c BaseCompiler) duplicateTransition(key any, rank int) (int, bool) {
+	if prevRank, ok := c.ranks[key]; !ok || prevRank != rank {
+		return 0, false
+	}
+	return c.lines.Transitions[key], true
+}
+
+// defineTransition records that transition for `key` with `rank` is defined in `line`, it returns false if
+// transition defined earlier takes precedence
+func (c *BaseCompiler) defineTransition(key any, rank int, line int) bool {
+	if prevRank, ok := c.ranks[key]; ok && prevRank < rank {
+		return false
+	}
+	c.ranks[key] = rank
+	c.lines.Transitions[key] = line
+	return true
+}
+
+// processTests processes optional tests section, each test case has following form:
+// (symbol1 symbol2 ...) > expectation
+//
+// `processTestCase` is called right after '(' of each test case has been processed, errors in test cases
+// are reported and processing continues with the next test case
+func (c *BaseCompiler) processTests(processTestCase func(line int) (TestCase, error)) error {
+	if c.peek().Type != lexer.TestsToken {
+		return nil
+	}
+	// Consume tests keyword
+	c.advance()
+	tests := make([]TestCase, 0)
+	for !c.isAtEnd() {
+		t := c.advance()
+		switch t.Type {
+		case lexer.SemicolonToken:
+			// Tests of the source with errors can't be run
+			if len(c.errs) == 0 {
+				c.tests = tests
+			}
+			return nil
+		case lexer.LeftParenToken:
+			tc, err := processTestCase(t.Line)
+			if err != nil {
+				c.recoverItem(err)
+				continue
+			}
+			tests = append(tests, tc)
+		default:
+			c.recoverItem(lexer.UnexpectedTokenErr(t, lexer.LeftParenToken, lexer.SemicolonToken))
+		}
+	}
+	return errors.New("missing ';' at the end of tests section")
+}
+
+// processTestInput processes symbols of test case input until ')' is reached, `allowed` are token types
+// that can be used in the input
+func (c *BaseCompiler) processTestInput(symbols map[string]automaton.Symbol, allowed ...lexer.TokenType) ([]string, error) {
+	const atEndErrMsg = "unfinished test case"
+	input := make([]string, 0)
+	for c.peek().Type != lexer.RightParenToken {
+		// RightParenToken is passed only for better error message
+		t, err := c.consumeTokenWithType(atEndErrMsg, append(allowed, lexer.RightParenToken)...)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := symbols[t.Value]; !ok {
+			return nil, fmt.Errorf("invalid symbol %s in test case input, each symbol must be defined in symbols section", t.Value)
+		}
+		input = append(input, t.Value)
+	}
+	// Consume ')'
+	c.advance()
+	if _, err := c.consumeTokenWithType(atEndErrMsg, lexer.ArrowToken); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// processTestAcceptance processes `accept` or `reject` expectation of the test case
+func (c *BaseCompiler) processTestAcceptance() (bool, error) {
+	t, err := c.consumeTokenWithType("unfinished test case", lexer.AcceptToken, lexer.RejectToken)
+	if err != nil {
+		return false, err
+	}
+	return t.Type == lexer.AcceptToken, nil
 }
 
 // checkTokenType returns an error if provided `t` is not one of the `expected` types
@@ -169,16 +696,8 @@ func checkTokenType(t lexer.Token, expected ...lexer.TokenType) error {
 	if expected == nil {
 		panic("no token type provided")
 	}
-	expectedStr := make([]string, 0, len(expected))
-	for _, v := range expected {
-		if t.Type == v {
-			return nil
-		}
-		expectedStr = append(expectedStr, v.String())
-	}
-	if len(expected) == 1 {
-		return fmt.Errorf("invalid token type, expected: %s, got: %s", expectedStr[0], t.Type.String())
+	if slices.Contains(expected, t.Type) {
+		return nil
 	}
-	all := strings.Join(expectedStr, ", ")
-	return fmt.Errorf("invalid token type, expected one of: %s, got: %s", all, t.Type.String())
+	return lexer.UnexpectedTokenErr(t, expected...)
 }
@@ -0,0 +1,295 @@
+package compiler
+
+import (
+	"automata-compiler/pkg/automaton"
+	"automata-compiler/pkg/lexer"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeclaredType(t *testing.T) {
+	data := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"header", "type TM;\nqA;", "TM"},
+		{"header after comment", "# Turing machine\ntype pa;\nqA;", "pa"},
+		{"no header", "qA;\nqA;", ""},
+		{"invalid header", "type qA;", ""},
+		{"lexing error", "type |", ""},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			result := DeclaredType(lexer.NewLexer(d.source))
+			if result != d.expected {
+				t.Errorf("invalid type, expected: %s, got: %s", d.expected, result)
+			}
+		})
+	}
+}
+
+func TestCompileHeader(t *testing.T) {
+	const dfaSource = "qA;\nqA;\nqA;\n0;\n(qA, 0) > (qA);\n0;\n"
+	data := []struct {
+		name           string
+		source         string
+		newCompiler    func(r lexer.TokenReader) Compiler
+		expectedErrMsg string
+	}{
+		{
+			"matching type",
+			"type DFA;\n" + dfaSource,
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			"",
+		},
+		{
+			"type is case insensitive",
+			"type dfa;\n" + dfaSource,
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			"",
+		},
+		{
+			"mismatched type stops compilation",
+			"type DFA;\n" + dfaSource,
+			func(r lexer.TokenReader) Compiler { return NewTuringMachineCompilerFromReader(r) },
+			"[Line 1, Column 6] source declares automaton type DFA, but it's compiled as TM",
+		},
+		{
+			"unknown type",
+			"type NFA;\n" + dfaSource,
+			func(r lexer.TokenReader) Compiler { return NewPushdownAutomatonCompilerFromReader(r) },
+			"[Line 1, Column 6] unknown automaton type NFA, expected one of: DFA, PA, TM",
+		},
+		{
+			"missing semicolon",
+			"type DFA\n" + dfaSource,
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			"[Line 2, Column 1] missing ';' after automaton type",
+		},
+		{
+			"header not at the start",
+			"qA;\ntype DFA;\n",
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			"[Line 2, Column 1] expected state name, got 'type'",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			_, err := d.newCompiler(lexer.NewLexer(d.source)).Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestCompileSections(t *testing.T) {
+	const positional = "qA qB;\nqA;\nqB;\n0 1;\n(qA, 0) > (qB)\n(qB, 1) > (qA);\n0 1 0;\ntests\n(0) > accept;\n"
+	data := []struct {
+		name   string
+		source string
+		// positional is the same source without section names, empty if compilation fails
+		positional     string
+		expectedErrMsg string
+	}{
+		{
+			"named sections in positional order",
+			"states: qA qB;\ninitial: qA;\naccepting: qB;\nsymbols: 0 1;\n" +
+				"transitions: (qA, 0) > (qB)\n(qB, 1) > (qA);\ninput: 0 1 0;\ntests\n(0) > accept;\n",
+			positional,
+			"",
+		},
+		{
+			"named sections in any order",
+			"transitions: (qA, 0) > (qB)\n(qB, 1) > (qA);\ninput: 0 1 0;\nsymbols: 0 1;\n" +
+				"accepting: qB;\ninitial: qA;\nstates: qA qB;\ntests\n(0) > accept;\n",
+			positional,
+			"",
+		},
+		{
+			"section names used as symbols",
+			"states: qA;\ninitial: qA;\naccepting: qA;\nsymbols: states input;\ntransitions: (qA, states) > (qA);\n",
+			"qA;\nqA;\nqA;\nstates input;\n(qA, states) > (qA);\n",
+			"",
+		},
+		{
+			"missing sections",
+			"states: qA qB;\nsymbols: 0 1;\ntransitions: (qA, 0) > (qB);\n",
+			"",
+			"initial section missing\naccepting section missing",
+		},
+		{
+			"duplicate section",
+			"states: qA;\ninitial: qA;\naccepting: qA;\nsymbols: 0;\ntransitions: ;\nstates: qB;\n",
+			"",
+			"[Line 6, Column 1] duplicate states section",
+		},
+		{
+			"unknown section",
+			"states: qA;\ninitial: qA;\naccepting: qA;\nalphabet: 0;\nsymbols: 0;\ntransitions: ;\n",
+			"",
+			"[Line 4, Column 1] unknown section alphabet, expected one of: states, initial, accepting, symbols, transitions, input",
+		},
+		{
+			"section without name after named sections",
+			"states: qA;\ninitial: qA;\naccepting: qA;\nsymbols: 0;\ntransitions: ;\n0 0;\n",
+			"",
+			"[Line 6, Column 1] expected section name, 'tests' or end of source, got symbol",
+		},
+		{
+			"errors in named sections",
+			"transitions: (qA, 2) > (qA);\nstates: qA;\ninitial: qB;\naccepting: qA;\nsymbols: 0;\n",
+			"",
+			"[Line 3, Column 10] invalid initial state, state qB was not declared in states list\n" +
+				"[Line 1, Column 19] undefined symbol 2 used in transition function left side",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			c := NewDeterministicFiniteAutomatonCompilerFromReader(lexer.NewLexer(d.source))
+			result, err := c.Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			if d.positional == "" {
+				return
+			}
+			// Named sections must compile to the same automaton as the positional ones
+			pc := NewDeterministicFiniteAutomatonCompilerFromReader(lexer.NewLexer(d.positional))
+			expected, err := pc.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(expected, result); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(pc.Tests(), c.Tests()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCompileQuotedSpecialSymbols(t *testing.T) {
+	data := []struct {
+		name           string
+		source         string
+		newCompiler    func(r lexer.TokenReader) Compiler
+		expectedErrMsg string
+	}{
+		{
+			"blank symbol declared in TM",
+			"qA;\nqA;\nqA;\n'B' 1;\n(qA, 1) > (qA, 1, R);\n1;\n",
+			func(r lexer.TokenReader) Compiler { return NewTuringMachineCompilerFromReader(r) },
+			"[Line 4, Column 1] quoted symbol B has the same name as a special symbol of the automaton",
+		},
+		{
+			"blank symbol used in TM",
+			"qA;\nqA;\nqA;\n1;\n(qA, 'B') > (qA, 1, R)\n(qA, 1) > (qA, 'B', R);\n1 'B';\ntests\n('B') > (1);\n",
+			func(r lexer.TokenReader) Compiler { return NewTuringMachineCompilerFromReader(r) },
+			"[Line 5, Column 6] quoted symbol B has the same name as a special symbol of the automaton\n" +
+				"[Line 6, Column 16] quoted symbol B has the same name as a special symbol of the automaton\n" +
+				"[Line 7, Column 3] quoted symbol B has the same name as a special symbol of the automaton\n" +
+				"[Line 9, Column 2] quoted symbol B has the same name as a special symbol of the automaton",
+		},
+		{
+			"quoted moves in TM",
+			"qA;\nqA;\nqA;\n'L' 'R';\n(qA, 'L') > (qA, 'R', R);\n",
+			func(r lexer.TokenReader) Compiler { return NewTuringMachineCompilerFromReader(r) },
+			"",
+		},
+		{
+			"input end and stack start in PA",
+			"qA;\nqA;\nqA;\n'{' a;\n(qA, a, '}') > (qA, '{');\n",
+			func(r lexer.TokenReader) Compiler { return NewPushdownAutomatonCompilerFromReader(r) },
+			"[Line 4, Column 1] quoted symbol { has the same name as a special symbol of the automaton\n" +
+				"[Line 5, Column 9] quoted symbol } has the same name as a special symbol of the automaton\n" +
+				"[Line 5, Column 21] quoted symbol { has the same name as a special symbol of the automaton",
+		},
+		{
+			"same symbols in DFA",
+			"qA;\nqA;\nqA;\n'B' '{' '}';\n(qA, 'B') > (qA)\n(qA, '{') > (qA)\n(qA, '}') > (qA);\n",
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			"",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			_, err := d.newCompiler(lexer.NewLexer(d.source)).Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestCompileInputQuotedSpecialSymbols(t *testing.T) {
+	c := NewTuringMachineCompilerFromReader(lexer.NewLexer("qA;\nqA;\nqA;\n1;\n(qA, 1) > (qA, 1, R);\n"))
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.CompileInputFromReader(lexer.NewLexer("1 'B'"))
+	expectedErrMsg := "[Line 1, Column 3] quoted symbol B has the same name as a special symbol of the automaton"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("invalid error message, expected: %s, got: %v", expectedErrMsg, err)
+	}
+}
+
+func TestCompileSymbolsStartingWithReservedLetters(t *testing.T) {
+	data := []struct {
+		name            string
+		source          string
+		newCompiler     func(r lexer.TokenReader) Compiler
+		expectedSymbols map[string]automaton.Symbol
+	}{
+		{
+			"DFA",
+			"qA;\nqA;\nqA;\nLeft Right B1;\n(qA, Left) > (qA)\n(qA, B1) > (qA);\nLeft B1 Right;\n",
+			func(r lexer.TokenReader) Compiler { return NewDeterministicFiniteAutomatonCompilerFromReader(r) },
+			map[string]automaton.Symbol{"Left": {Name: "Left"}, "Right": {Name: "Right"}, "B1": {Name: "B1"}},
+		},
+		{
+			"TM",
+			"qA;\nqA;\nqA;\nLeft Right B1;\n(qA, Left) > (qA, Right, R)\n(qA, B1) > (qA, B, L);\nLeft B1;\n",
+			func(r lexer.TokenReader) Compiler { return NewTuringMachineCompilerFromReader(r) },
+			map[string]automaton.Symbol{
+				"Left":                     {Name: "Left"},
+				"Right":                    {Name: "Right"},
+				"B1":                       {Name: "B1"},
+				automaton.BlankSymbol.Name: automaton.BlankSymbol,
+			},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			a, err := d.newCompiler(lexer.NewLexer(d.source)).Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var symbols map[string]automaton.Symbol
+			switch a := a.(type) {
+			case *automaton.DeterministicFiniteAutomaton:
+				symbols = a.Symbols
+			case *automaton.TuringMachine:
+				symbols = a.Symbols
+			}
+			if diff := cmp.Diff(d.expectedSymbols, symbols); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
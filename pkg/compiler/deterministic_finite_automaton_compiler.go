@@ -5,6 +5,7 @@ import (
 	"automata-compiler/pkg/lexer"
 	"errors"
 	"fmt"
+	"slices"
 )
 
 type DeterministicFiniteAutomatonCompiler struct {
@@ -12,41 +13,76 @@ type DeterministicFiniteAutomatonCompiler struct {
 }
 
 func NewDeterministicFiniteAutomatonCompiler(tokens []lexer.Token) *DeterministicFiniteAutomatonCompiler {
-	return &DeterministicFiniteAutomatonCompiler{BaseCompiler: newBaseCompiler(tokens)}
+	return &DeterministicFiniteAutomatonCompiler{BaseCompiler: newBaseCompiler(tokens, nil)}
+}
+
+// NewDeterministicFiniteAutomatonCompilerFromReader creates compiler reading tokens one by one from `r`
+func NewDeterministicFiniteAutomatonCompilerFromReader(r lexer.TokenReader) *DeterministicFiniteAutomatonCompiler {
+	return &DeterministicFiniteAutomatonCompiler{BaseCompiler: newBaseCompilerFromReader(r, nil)}
 }
 
 func (dfa *DeterministicFiniteAutomatonCompiler) Compile() (automaton.Automaton, error) {
+	// Errors are reported and processing continues with the next section, so all errors are returned at once
+	dfa.processHeader("DFA")
+	dfa.processSections()
 	states, err := dfa.processStates()
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
-	}
+	dfa.recoverSection(err)
 	initialState, err := dfa.processInitialState(states)
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
-	}
+	dfa.recoverSection(err)
 	err = dfa.processAcceptingStates(states)
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
-	}
+	dfa.recoverSection(err)
 	// DFA doesn't have any special symbol so we pass an empty map
 	symbols, err := dfa.processSymbols(make(map[string]automaton.Symbol))
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
-	}
+	dfa.recoverSection(err)
 	tf, err := dfa.processTransitions(states, symbols)
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
+	dfa.recoverSection(err)
+	newAutomaton := func(input []string) automaton.Automaton {
+		return newDeterministicFiniteAutomaton(states, symbols, initialState, tf, input)
+	}
+	var input []string
+	if !dfa.isInputOmitted() {
+		input, err = dfa.processInput(symbols, lexer.SemicolonToken)
+		dfa.recoverSection(err)
+	}
+	err = dfa.processTests(func(line int) (TestCase, error) {
+		return dfa.processTestCase(line, symbols, newAutomaton)
+	})
+	dfa.recoverSection(err)
+	if err := dfa.finish(); err != nil {
+		return nil, err
 	}
-	input, err := dfa.processInput(symbols)
-	if err != nil {
-		return nil, dfa.addLinePrefixForErrPrevToken(err)
+	dfa.symbols = symbols
+	dfa.newAutomaton = newAutomaton
+	return newAutomaton(input), nil
+}
+
+func (dfa *DeterministicFiniteAutomatonCompiler) CompileInput(tokens []lexer.Token) (automaton.Automaton, error) {
+	return dfa.compileInput(NewDeterministicFiniteAutomatonCompiler(tokens))
+}
+
+func (dfa *DeterministicFiniteAutomatonCompiler) CompileInputFromReader(r lexer.TokenReader) (automaton.Automaton, error) {
+	return dfa.compileInput(NewDeterministicFiniteAutomatonCompilerFromReader(r))
+}
+
+// compileInput processes input with `ic`, compiler created for the input tokens
+func (dfa *DeterministicFiniteAutomatonCompiler) compileInput(ic *DeterministicFiniteAutomatonCompiler) (automaton.Automaton, error) {
+	if err := dfa.checkCompiled(); err != nil {
+		return nil, err
+	}
+	input, err := ic.processInput(dfa.symbols, lexer.EOFToken)
+	if ic.lexErr != nil {
+		return nil, ic.lexErr
 	}
-	err = dfa.checkForCorrectEndingSequnce()
 	if err != nil {
-		// It's more lexer error than user provided source,
-		// so we don't include line here
-		return nil, err
+		return nil, ic.addLinePrefixForErrPrevToken(err)
 	}
+	if len(ic.errs) > 0 {
+		return nil, ic.errs[0]
+	}
+	return dfa.newAutomaton(input), nil
+}
+
+func newDeterministicFiniteAutomaton(states map[string]automaton.State, symbols map[string]automaton.Symbol, initialState string, tf automaton.DFATransitionFunction, input []string) *automaton.DeterministicFiniteAutomaton {
 	return &automaton.DeterministicFiniteAutomaton{
 		States:       states,
 		Symbols:      symbols,
@@ -54,6 +90,26 @@ func (dfa *DeterministicFiniteAutomatonCompiler) Compile() (automaton.Automaton,
 		Input:        input,
 		InputIt:      0,
 		Transitions:  tf,
+	}
+}
+
+func (dfa *DeterministicFiniteAutomatonCompiler) processTestCase(line int, symbols map[string]automaton.Symbol, newAutomaton func(input []string) automaton.Automaton) (TestCase, error) {
+	// Each test case is as follows:
+	// (symbol1 symbol2 ...) > accept|reject
+	// At this point '(' has already been processed
+	input, err := dfa.processTestInput(symbols, lexer.SymbolToken)
+	if err != nil {
+		return TestCase{}, err
+	}
+	accepted, err := dfa.processTestAcceptance()
+	if err != nil {
+		return TestCase{}, err
+	}
+	return TestCase{
+		Line:             line,
+		Input:            input,
+		Automaton:        newAutomaton(slices.Clone(input)),
+		ExpectedAccepted: accepted,
 	}, nil
 }
 
@@ -65,27 +121,33 @@ func (dfa *DeterministicFiniteAutomatonCompiler) processTransitions(states map[s
 		case lexer.SemicolonToken:
 			return tf, nil
 		case lexer.LeftParenToken:
-			err := dfa.processSingleTransition(states, symbols, tf)
+			err := dfa.processSingleTransition(t.Line, states, symbols, tf)
 			if err != nil {
-				return nil, err
+				dfa.recoverItem(err)
 			}
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.LeftParenToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			dfa.recoverItem(lexer.UnexpectedTokenErr(t, lexer.LeftParenToken, lexer.SemicolonToken))
 
 		}
 	}
-	return nil, errors.New("missing ';' at the end of transitions section")
+	return tf, errors.New("missing ';' at the end of transitions section")
 }
 
-func (dfa *DeterministicFiniteAutomatonCompiler) processSingleTransition(states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.DFATransitionFunction) error {
+func (dfa *DeterministicFiniteAutomatonCompiler) processSingleTransition(line int, states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.DFATransitionFunction) error {
 	// Each transition is as follows:
 	// (state, symbol) > (state)
+	// where symbol can be also a class of symbols, e.g. {symbol1, symbol2}, or * matching any symbol
 	// At this point '(' has already been processed
 	const atEndErrMsg = "unfinished transition"
-	leftSide, err := dfa.processTransitionLeftSide(states, symbols, atEndErrMsg)
+	leftSides, rank, err := dfa.processTransitionLeftSide(states, symbols, atEndErrMsg)
 	if err != nil {
 		return err
 	}
+	for _, leftSide := range leftSides {
+		if prevLine, ok := dfa.duplicateTransition(leftSide, rank); ok {
+			return fmt.Errorf("duplicate transition for state %s and symbol %s, already defined in line %d", leftSide.StateName, leftSide.SymbolName, prevLine)
+		}
+	}
 	if _, err := dfa.consumeTokenWithType(atEndErrMsg, lexer.ArrowToken); err != nil {
 		return err
 	}
@@ -93,33 +155,41 @@ func (dfa *DeterministicFiniteAutomatonCompiler) processSingleTransition(states
 	if err != nil {
 		return err
 	}
-	tf[leftSide] = rightSide
+	for _, leftSide := range leftSides {
+		if dfa.defineTransition(leftSide, rank, line) {
+			tf[leftSide] = rightSide
+		}
+	}
 	return nil
 }
 
-func (dfa *DeterministicFiniteAutomatonCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.DFATransitionKey, error) {
-	var zero automaton.DFATransitionKey
+// processTransitionLeftSide returns keys of all transitions matched by the left side and its rank
+func (dfa *DeterministicFiniteAutomatonCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) ([]automaton.DFATransitionKey, int, error) {
 	state, err := dfa.consumeTokenWithType(atEndErrMsg, lexer.StateToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
 	if _, ok := states[state.Value]; !ok {
-		return zero, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
+		return nil, 0, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
 	}
 	if _, err := dfa.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	symbol, err := dfa.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken)
+	symbol, err := dfa.processSymbolPattern(wildcardSymbols(symbols), atEndErrMsg, lexer.SymbolToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	if _, ok := symbols[symbol.Value]; !ok {
-		return zero, fmt.Errorf("undefined symbol %s used in transition function left side", symbol.Value)
+	keys := make([]automaton.DFATransitionKey, 0, len(symbol.symbols))
+	for _, name := range symbol.symbols {
+		if _, ok := symbols[name]; !ok {
+			return nil, 0, fmt.Errorf("undefined symbol %s used in transition function left side", name)
+		}
+		keys = append(keys, automaton.DFATransitionKey{StateName: state.Value, SymbolName: name})
 	}
 	if _, err := dfa.consumeTokenWithType(atEndErrMsg, lexer.RightParenToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	return automaton.DFATransitionKey{StateName: state.Value, SymbolName: symbol.Value}, nil
+	return keys, symbol.rank, nil
 }
 
 func (dfa *DeterministicFiniteAutomatonCompiler) processTransitionRightSide(states map[string]automaton.State, atEndErrMsg string) (automaton.DFATransitionValue, error) {
@@ -140,15 +210,14 @@ func (dfa *DeterministicFiniteAutomatonCompiler) processTransitionRightSide(stat
 	return automaton.DFATransitionValue{StateName: state.Value}, nil
 }
 
-func (dfa *DeterministicFiniteAutomatonCompiler) processInput(symbols map[string]automaton.Symbol) ([]string, error) {
+// processInput processes symbols until `end` token is reached, `end` is either ';' for the section in the source or EOF
+// for the input provided separately
+func (dfa *DeterministicFiniteAutomatonCompiler) processInput(symbols map[string]automaton.Symbol, end lexer.TokenType) ([]string, error) {
 	input := make([]string, 0)
 	for !dfa.isAtEnd() {
 		t := dfa.advance()
 		switch t.Type {
-		case lexer.SemicolonToken:
-			if len(input) == 0 {
-				input = append(input, automaton.BlankSymbol.Name)
-			}
+		case end:
 			return input, nil
 		case lexer.SymbolToken:
 			if _, ok := symbols[t.Value]; !ok {
@@ -156,7 +225,7 @@ func (dfa *DeterministicFiniteAutomatonCompiler) processInput(symbols map[string
 			}
 			input = append(input, t.Value)
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.SemicolonToken.String(), lexer.SymbolToken.String(), t.Type.String())
+			return nil, lexer.UnexpectedTokenErr(t, end, lexer.SymbolToken)
 		}
 	}
 	return nil, errors.New("missing ';' at the end of input section")
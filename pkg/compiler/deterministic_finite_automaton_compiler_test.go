@@ -127,6 +127,86 @@ func TestCompileDFA(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"reserved letters of other automata used as symbols",
+			[]lexer.Token{
+				// States
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				// Initial state
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				// Accepting states
+				{Type: lexer.StateToken, Value: "qState", Line: 3},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				// Symbols
+				{Type: lexer.MoveLeftToken, Value: "L", Line: 4},
+				{Type: lexer.MoveRightToken, Value: "R", Line: 4},
+				{Type: lexer.BlankSymbolToken, Value: "B", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				// Transitions
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.BlankSymbolToken, Value: "B", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.ArrowToken, Value: ">", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+				// Input
+				{Type: lexer.MoveLeftToken, Value: "L", Line: 6},
+				{Type: lexer.BlankSymbolToken, Value: "B", Line: 6},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+				{Type: lexer.EOFToken, Value: "", Line: 6},
+			},
+			&automaton.DeterministicFiniteAutomaton{
+				States: map[string]automaton.State{
+					"qState": {Name: "qState", Accepting: true},
+				},
+				CurrentState: "qState",
+				Symbols: map[string]automaton.Symbol{
+					"L": {Name: "L"},
+					"R": {Name: "R"},
+					"B": {Name: "B"},
+				},
+				Transitions: map[automaton.DFATransitionKey]automaton.DFATransitionValue{
+					{StateName: "qState", SymbolName: "B"}: {StateName: "qState"},
+				},
+				Input:   []string{"L", "B"},
+				InputIt: 0,
+			},
+			"",
+		},
+		{
+			"empty input",
+			[]lexer.Token{
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+				{Type: lexer.EOFToken, Value: "", Line: 6},
+			},
+			&automaton.DeterministicFiniteAutomaton{
+				States: map[string]automaton.State{
+					"qState": {Name: "qState"},
+				},
+				CurrentState: "qState",
+				Symbols: map[string]automaton.Symbol{
+					"symbol1": {Name: "symbol1"},
+				},
+				Transitions: map[automaton.DFATransitionKey]automaton.DFATransitionValue{},
+				Input:       []string{},
+				InputIt:     0,
+			},
+			"",
+		},
 		{
 			"missing semicolon after states",
 			[]lexer.Token{
@@ -154,7 +234,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "Symbol", Line: 1},
 			},
 			nil,
-			"[Line 1] invalid token type, expected: StateToken or SemicolonToken, got: SymbolToken",
+			"[Line 1] expected state name or ';', got symbol",
 		},
 		{
 			"no state defined",
@@ -187,7 +267,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken, got: SymbolToken",
+			"[Line 3] expected state name, got symbol",
 		},
 		{
 			"unknown state in initial state section",
@@ -244,7 +324,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken or SemicolonToken, got: ArrowToken",
+			"[Line 3] expected state name or ';', got '>'",
 		},
 		{
 			"undefined state in accepting states section",
@@ -301,7 +381,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: LeftParenToken",
+			"[Line 5] expected symbol or ';', got '('",
 		},
 		{
 			"duplicated symbol in symbols section",
@@ -383,7 +463,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol3", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: LeftParenToken or SemicolonToken, got: SymbolToken",
+			"[Line 5] expected '(' or ';', got symbol",
 		},
 		{
 			"unfinished transition",
@@ -415,6 +495,47 @@ func TestCompileDFA(t *testing.T) {
 			nil,
 			"[Line 6] unfinished transition",
 		},
+		{
+			"duplicate transition",
+			[]lexer.Token{
+				// States
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.StateToken, Value: "qState2", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				// Initial state
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				// Accepting states
+				{Type: lexer.StateToken, Value: "qState2", Line: 3},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				// Symbols
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				// Transitions
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.ArrowToken, Value: ">", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState2", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.ArrowToken, Value: ">", Line: 6},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+				{Type: lexer.EOFToken, Value: "", Line: 6},
+			},
+			nil,
+			"[Line 6] duplicate transition for state qState and symbol symbol1, already defined in line 5",
+		},
 		{
 			"undefined state in left side transition",
 			[]lexer.Token{
@@ -597,7 +718,7 @@ func TestCompileDFA(t *testing.T) {
 				{Type: lexer.ArrowToken, Value: ">", Line: 7},
 			},
 			nil,
-			"[Line 7] invalid token type, expected: SemicolonToken or SymbolToken, got: ArrowToken",
+			"[Line 7] expected ';' or symbol, got '>'",
 		},
 		{
 			"missing EOF at the end of token list",
@@ -680,3 +801,228 @@ func TestCompileDFA(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileTestsDFA(t *testing.T) {
+	tokens := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Accepting states
+		{Type: lexer.StateToken, Value: "qState", Line: 3},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Transitions
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Input
+		{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+		// Tests
+		{Type: lexer.TestsToken, Value: "tests", Line: 7},
+		{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 8},
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 8},
+		{Type: lexer.RightParenToken, Value: ")", Line: 8},
+		{Type: lexer.ArrowToken, Value: ">", Line: 8},
+		{Type: lexer.AcceptToken, Value: "accept", Line: 8},
+		{Type: lexer.LeftParenToken, Value: "(", Line: 9},
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 9},
+		{Type: lexer.RightParenToken, Value: ")", Line: 9},
+		{Type: lexer.ArrowToken, Value: ">", Line: 9},
+		{Type: lexer.RejectToken, Value: "reject", Line: 9},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 9},
+		{Type: lexer.EOFToken, Value: "", Line: 9},
+	}
+	c := NewDeterministicFiniteAutomatonCompiler(tokens)
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	states := map[string]automaton.State{
+		"qState": {Name: "qState", Accepting: true},
+	}
+	symbols := map[string]automaton.Symbol{
+		"symbol1": {Name: "symbol1"},
+	}
+	expected := []TestCase{
+		{
+			Line:  8,
+			Input: []string{"symbol1", "symbol1"},
+			Automaton: &automaton.DeterministicFiniteAutomaton{
+				States:       states,
+				Symbols:      symbols,
+				CurrentState: "qState",
+				Input:        []string{"symbol1", "symbol1"},
+				Transitions:  automaton.DFATransitionFunction{},
+			},
+			ExpectedAccepted: true,
+		},
+		{
+			Line:  9,
+			Input: []string{"symbol1"},
+			Automaton: &automaton.DeterministicFiniteAutomaton{
+				States:       states,
+				Symbols:      symbols,
+				CurrentState: "qState",
+				Input:        []string{"symbol1"},
+				Transitions:  automaton.DFATransitionFunction{},
+			},
+			ExpectedAccepted: false,
+		},
+	}
+	if diff := cmp.Diff(expected, c.Tests()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCompileInputDFA(t *testing.T) {
+	source := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Accepting states
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Transitions
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Input is omitted
+		{Type: lexer.EOFToken, Value: "", Line: 5},
+	}
+	data := []struct {
+		name           string
+		input          []lexer.Token
+		expected       *automaton.DeterministicFiniteAutomaton
+		expectedErrMsg string
+	}{
+		{
+			"valid input",
+			[]lexer.Token{
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+				{Type: lexer.EOFToken, Value: "", Line: 1},
+			},
+			&automaton.DeterministicFiniteAutomaton{
+				States: map[string]automaton.State{
+					"qState": {Name: "qState"},
+				},
+				Symbols: map[string]automaton.Symbol{
+					"symbol1": {Name: "symbol1"},
+				},
+				CurrentState: "qState",
+				Input:        []string{"symbol1", "symbol1"},
+				Transitions:  automaton.DFATransitionFunction{},
+			},
+			"",
+		},
+		{
+			"undefined symbol",
+			[]lexer.Token{
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+				{Type: lexer.SymbolToken, Value: "symbol2", Line: 2},
+				{Type: lexer.EOFToken, Value: "", Line: 2},
+			},
+			nil,
+			"[Line 2] invalid symbol symbol2 in input, each symbol must be defined in symbols section",
+		},
+		{
+			"invalid token type",
+			[]lexer.Token{
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				{Type: lexer.EOFToken, Value: "", Line: 1},
+			},
+			nil,
+			"[Line 1] expected end of source or symbol, got ';'",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			c := NewDeterministicFiniteAutomatonCompiler(source)
+			if _, err := c.Compile(); err != nil {
+				t.Fatal(err)
+			}
+			result, err := c.CompileInput(d.input)
+			if !(d.expected == nil && result == nil) {
+				if diff := cmp.Diff(d.expected, result); diff != "" {
+					t.Error(diff)
+				}
+			}
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestCompileInputBeforeCompileDFA(t *testing.T) {
+	c := NewDeterministicFiniteAutomatonCompiler(nil)
+	_, err := c.CompileInput([]lexer.Token{{Type: lexer.EOFToken, Value: "", Line: 1}})
+	expectedErrMsg := "source must be compiled before compiling input"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("invalid error message, expected: %s, got: %v", expectedErrMsg, err)
+	}
+}
+
+func TestCompileWildcardsDFA(t *testing.T) {
+	const header = "qA qB;\nqA;\nqB;\n0 1 2;\n"
+	data := []struct {
+		name           string
+		transitions    string
+		expected       automaton.DFATransitionFunction
+		expectedErrMsg string
+	}{
+		{
+			"wildcard, class and explicit symbol",
+			"(qA, *) > (qA)\n(qA, {1, 2}) > (qB)\n(qA, 2) > (qA)\n(qB, {0}) > (qA)",
+			automaton.DFATransitionFunction{
+				{StateName: "qA", SymbolName: "0"}: {StateName: "qA"},
+				{StateName: "qA", SymbolName: "1"}: {StateName: "qB"},
+				{StateName: "qA", SymbolName: "2"}: {StateName: "qA"},
+				{StateName: "qB", SymbolName: "0"}: {StateName: "qA"},
+			},
+			"",
+		},
+		{
+			"duplicate explicit transition",
+			"(qA, *) > (qA)\n(qA, 1) > (qB)\n(qA, 1) > (qA)",
+			nil,
+			"[Line 7, Column 7] duplicate transition for state qA and symbol 1, already defined in line 6",
+		},
+		{
+			"empty class",
+			"(qA, {}) > (qA)",
+			nil,
+			"[Line 5, Column 6] expected symbol, got input end symbol '{'",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			source := header + d.transitions + ";\n"
+			c := NewDeterministicFiniteAutomatonCompilerFromReader(lexer.NewLexer(source))
+			result, err := c.Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(d.expected, result.(*automaton.DeterministicFiniteAutomaton).Transitions); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"automata-compiler/pkg/lexer"
 	"errors"
 	"fmt"
+	"slices"
 )
 
 type PushdownAutomatonCompiler struct {
@@ -12,52 +13,110 @@ type PushdownAutomatonCompiler struct {
 }
 
 func NewPushdownAutomatonCompiler(tokens []lexer.Token) *PushdownAutomatonCompiler {
-	return &PushdownAutomatonCompiler{BaseCompiler: newBaseCompiler(tokens)}
+	return &PushdownAutomatonCompiler{BaseCompiler: newBaseCompiler(tokens, paSpecialSymbols)}
+}
+
+// NewPushdownAutomatonCompilerFromReader creates compiler reading tokens one by one from `r`
+func NewPushdownAutomatonCompilerFromReader(r lexer.TokenReader) *PushdownAutomatonCompiler {
+	return &PushdownAutomatonCompiler{BaseCompiler: newBaseCompilerFromReader(r, paSpecialSymbols)}
 }
 
 func (pa *PushdownAutomatonCompiler) Compile() (automaton.Automaton, error) {
+	// Errors are reported and processing continues with the next section, so all errors are returned at once
+	pa.processHeader("PA")
+	pa.processSections()
 	states, err := pa.processStates()
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
-	}
+	pa.recoverSection(err)
 	initialState, err := pa.processInitialState(states)
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
-	}
+	pa.recoverSection(err)
 	err = pa.processAcceptingStates(states)
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
-	}
+	pa.recoverSection(err)
 	specialSymbols := pa.getSpecialSymbols()
 	symbols, err := pa.processSymbols(specialSymbols)
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
-	}
+	pa.recoverSection(err)
 	tf, err := pa.processTransitions(states, symbols)
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
+	pa.recoverSection(err)
+	newAutomaton := func(input []string) automaton.Automaton {
+		return newPushdownAutomaton(states, symbols, initialState, tf, input)
 	}
-	initialInput, err := pa.processInput(symbols)
-	if err != nil {
-		return nil, pa.addLinePrefixForErrPrevToken(err)
+	var initialInput []string
+	if !pa.isInputOmitted() {
+		initialInput, err = pa.processInput(symbols, lexer.SemicolonToken)
+		pa.recoverSection(err)
 	}
-	err = pa.checkForCorrectEndingSequnce()
-	if err != nil {
-		// It's more lexer error than user provided source,
-		// so we don't include line here
+	err = pa.processTests(func(line int) (TestCase, error) {
+		return pa.processTestCase(line, symbols, newAutomaton)
+	})
+	pa.recoverSection(err)
+	if err := pa.finish(); err != nil {
 		return nil, err
 	}
+	pa.symbols = symbols
+	pa.newAutomaton = newAutomaton
+	return newAutomaton(initialInput), nil
+}
+
+func (pa *PushdownAutomatonCompiler) CompileInput(tokens []lexer.Token) (automaton.Automaton, error) {
+	return pa.compileInput(NewPushdownAutomatonCompiler(tokens))
+}
+
+func (pa *PushdownAutomatonCompiler) CompileInputFromReader(r lexer.TokenReader) (automaton.Automaton, error) {
+	return pa.compileInput(NewPushdownAutomatonCompilerFromReader(r))
+}
+
+// compileInput processes input with `ic`, compiler created for the input tokens
+func (pa *PushdownAutomatonCompiler) compileInput(ic *PushdownAutomatonCompiler) (automaton.Automaton, error) {
+	if err := pa.checkCompiled(); err != nil {
+		return nil, err
+	}
+	input, err := ic.processInput(pa.symbols, lexer.EOFToken)
+	if ic.lexErr != nil {
+		return nil, ic.lexErr
+	}
+	if err != nil {
+		return nil, ic.addLinePrefixForErrPrevToken(err)
+	}
+	if len(ic.errs) > 0 {
+		return nil, ic.errs[0]
+	}
+	return pa.newAutomaton(input), nil
+}
+
+func newPushdownAutomaton(states map[string]automaton.State, symbols map[string]automaton.Symbol, initialState string, tf automaton.PATransitionFunction, input []string) *automaton.PushdownAutomaton {
 	return &automaton.PushdownAutomaton{
 		States:       states,
 		Symbols:      symbols,
 		CurrentState: initialState,
-		Input:        initialInput,
+		Input:        append(input, automaton.InputEndSymbol.Name),
 		InputIt:      0,
 		Stack:        []string{automaton.StackStartSymbol.Name},
 		Transitions:  tf,
+	}
+}
+
+func (pa *PushdownAutomatonCompiler) processTestCase(line int, symbols map[string]automaton.Symbol, newAutomaton func(input []string) automaton.Automaton) (TestCase, error) {
+	// Each test case is as follows:
+	// (symbol1 symbol2 ...) > accept|reject
+	// At this point '(' has already been processed
+	input, err := pa.processTestInput(symbols, lexer.SymbolToken)
+	if err != nil {
+		return TestCase{}, err
+	}
+	accepted, err := pa.processTestAcceptance()
+	if err != nil {
+		return TestCase{}, err
+	}
+	return TestCase{
+		Line:             line,
+		Input:            input,
+		Automaton:        newAutomaton(slices.Clone(input)),
+		ExpectedAccepted: accepted,
 	}, nil
 }
 
+// paSpecialSymbols are names of symbols returned by `getSpecialSymbols`
+var paSpecialSymbols = []string{automaton.InputEndSymbol.Name, automaton.StackStartSymbol.Name}
+
 func (pa PushdownAutomatonCompiler) getSpecialSymbols() map[string]automaton.Symbol {
 	symbols := make(map[string]automaton.Symbol)
 	symbols[automaton.InputEndSymbol.Name] = automaton.InputEndSymbol
@@ -73,119 +132,151 @@ func (pa *PushdownAutomatonCompiler) processTransitions(states map[string]automa
 		case lexer.SemicolonToken:
 			return tf, nil
 		case lexer.LeftParenToken:
-			err := pa.processSingleTransition(states, symbols, tf)
+			err := pa.processSingleTransition(t.Line, states, symbols, tf)
 			if err != nil {
-				return nil, err
+				pa.recoverItem(err)
 			}
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.LeftParenToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			pa.recoverItem(lexer.UnexpectedTokenErr(t, lexer.LeftParenToken, lexer.SemicolonToken))
 		}
 	}
-	return nil, errors.New("missing ';' at the end of transitions section")
+	return tf, errors.New("missing ';' at the end of transitions section")
 }
 
-func (pa *PushdownAutomatonCompiler) processSingleTransition(states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.PATransitionFunction) error {
+func (pa *PushdownAutomatonCompiler) processSingleTransition(line int, states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.PATransitionFunction) error {
 	// Each transition is as follows:
 	// (state, input_symbol, stack_symbol) > (state, stack_symbol1, stack_symbol2, ...)
+	// where symbols on the left side can be also classes of symbols, e.g. {symbol1, symbol2}, or * matching any
+	// symbol, and * on the right side pushes back the stack symbol that was read
 	// At this point '(' has already been processed
 	const atEndErrMsg = "unfinished transition"
-	leftSide, err := pa.processTransitionLeftSide(states, symbols, atEndErrMsg)
+	leftSides, rank, err := pa.processTransitionLeftSide(states, symbols, atEndErrMsg)
 	if err != nil {
 		return err
 	}
+	for _, leftSide := range leftSides {
+		if prevLine, ok := pa.duplicateTransition(leftSide, rank); ok {
+			return fmt.Errorf("duplicate transition for state %s, symbol %s and stack symbol %s, already defined in line %d", leftSide.StateName, leftSide.InputSymbolName, leftSide.StackSymbolName, prevLine)
+		}
+	}
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.ArrowToken); err != nil {
 		return err
 	}
-	rightSide, err := pa.processTransitionRightSide(states, symbols, atEndErrMsg)
+	rightSide, pushBack, err := pa.processTransitionRightSide(states, symbols, atEndErrMsg)
 	if err != nil {
 		return err
 	}
-	tf[leftSide] = rightSide
+	for _, leftSide := range leftSides {
+		if !pa.defineTransition(leftSide, rank, line) {
+			continue
+		}
+		value := automaton.PATransitionValue{StateName: rightSide.StateName, StackSymbolNames: slices.Clone(rightSide.StackSymbolNames)}
+		for _, i := range pushBack {
+			value.StackSymbolNames[i] = leftSide.StackSymbolName
+		}
+		tf[leftSide] = value
+	}
 	return nil
 }
 
-func (pa *PushdownAutomatonCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.PATransitionKey, error) {
-	var zero automaton.PATransitionKey
+// processTransitionLeftSide returns keys of all transitions matched by the left side and its rank
+func (pa *PushdownAutomatonCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) ([]automaton.PATransitionKey, int, error) {
 	state, err := pa.consumeTokenWithType(atEndErrMsg, lexer.StateToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
 	if _, ok := states[state.Value]; !ok {
-		return zero, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
+		return nil, 0, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
 	}
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	inputSymbol, err := pa.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.InputEndToken)
+	// Stack start symbol is never read from the input and input end symbol is never pushed onto the stack
+	inputSymbol, err := pa.processSymbolPattern(wildcardSymbols(symbols, automaton.StackStartSymbol.Name), atEndErrMsg, lexer.SymbolToken, lexer.InputEndToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	if _, ok := symbols[inputSymbol.Value]; !ok {
-		return zero, fmt.Errorf("undefined input symbol %s used in transition function left side", inputSymbol.Value)
+	for _, name := range inputSymbol.symbols {
+		if _, ok := symbols[name]; !ok {
+			return nil, 0, fmt.Errorf("undefined input symbol %s used in transition function left side", name)
+		}
 	}
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	stackSymbol, err := pa.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.StackStartToken)
+	stackSymbol, err := pa.processSymbolPattern(wildcardSymbols(symbols, automaton.InputEndSymbol.Name), atEndErrMsg, lexer.SymbolToken, lexer.StackStartToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	if _, ok := symbols[stackSymbol.Value]; !ok {
-		return zero, fmt.Errorf("undefined stack symbol %s used in transition function left side", stackSymbol.Value)
+	for _, name := range stackSymbol.symbols {
+		if _, ok := symbols[name]; !ok {
+			return nil, 0, fmt.Errorf("undefined stack symbol %s used in transition function left side", name)
+		}
 	}
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.RightParenToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	return automaton.PATransitionKey{
-		StateName:       state.Value,
-		InputSymbolName: inputSymbol.Value,
-		StackSymbolName: stackSymbol.Value,
-	}, nil
+	keys := make([]automaton.PATransitionKey, 0, len(inputSymbol.symbols)*len(stackSymbol.symbols))
+	for _, input := range inputSymbol.symbols {
+		for _, stack := range stackSymbol.symbols {
+			keys = append(keys, automaton.PATransitionKey{StateName: state.Value, InputSymbolName: input, StackSymbolName: stack})
+		}
+	}
+	return keys, inputSymbol.rank + stackSymbol.rank, nil
 }
 
-func (pa *PushdownAutomatonCompiler) processTransitionRightSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.PATransitionValue, error) {
+// processTransitionRightSide returns the right side of the transition and indexes of `*` among pushed symbols,
+// which stand for the stack symbol that was read and are left empty in the returned value
+func (pa *PushdownAutomatonCompiler) processTransitionRightSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.PATransitionValue, []int, error) {
 	var zero automaton.PATransitionValue
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.LeftParenToken); err != nil {
-		return zero, err
+		return zero, nil, err
 	}
 	state, err := pa.consumeTokenWithType(atEndErrMsg, lexer.StateToken)
 	if err != nil {
-		return zero, err
+		return zero, nil, err
 	}
 	if _, ok := states[state.Value]; !ok {
-		return zero, fmt.Errorf("undefined state %s used in transition function right side", state.Value)
+		return zero, nil, fmt.Errorf("undefined state %s used in transition function right side", state.Value)
 	}
 	stackSymbols := make([]string, 0)
+	pushBack := make([]int, 0)
 	for pa.peek().Type == lexer.CommaToken {
 		// Consume comma
 		pa.advance()
-		stackSymbol, err := pa.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.StackStartToken)
+		stackSymbol, err := pa.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.StackStartToken, lexer.WildcardToken)
 		if err != nil {
-			return zero, err
+			return zero, nil, err
+		}
+		if stackSymbol.Type == lexer.WildcardToken {
+			pushBack = append(pushBack, len(stackSymbols))
+			stackSymbols = append(stackSymbols, "")
+			continue
 		}
 		if _, ok := symbols[stackSymbol.Value]; !ok {
-			return zero, fmt.Errorf("undefined stack symbol %s used in transition function right side", stackSymbol.Value)
+			return zero, nil, fmt.Errorf("undefined stack symbol %s used in transition function right side", stackSymbol.Value)
 		}
 		stackSymbols = append(stackSymbols, stackSymbol.Value)
 	}
 	// We pass CommaToken here only for better error message, at this point we know it can
 	// only be RightParenToken
 	if _, err := pa.consumeTokenWithType(atEndErrMsg, lexer.RightParenToken, lexer.CommaToken); err != nil {
-		return zero, err
+		return zero, nil, err
 	}
 	return automaton.PATransitionValue{
 		StateName:        state.Value,
 		StackSymbolNames: stackSymbols,
-	}, nil
+	}, pushBack, nil
 }
 
-func (pa *PushdownAutomatonCompiler) processInput(symbols map[string]automaton.Symbol) ([]string, error) {
+// processInput processes symbols until `end` token is reached, `end` is either ';' for the section in the source or EOF
+// for the input provided separately
+func (pa *PushdownAutomatonCompiler) processInput(symbols map[string]automaton.Symbol, end lexer.TokenType) ([]string, error) {
 	input := make([]string, 0)
 	for !pa.isAtEnd() {
 		t := pa.advance()
 		switch t.Type {
-		case lexer.SemicolonToken:
-			input = append(input, automaton.InputEndSymbol.Name)
+		case end:
 			return input, nil
 		case lexer.SymbolToken:
 			if _, ok := symbols[t.Value]; !ok {
@@ -193,7 +284,7 @@ func (pa *PushdownAutomatonCompiler) processInput(symbols map[string]automaton.S
 			}
 			input = append(input, t.Value)
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.SemicolonToken.String(), lexer.SymbolToken.String(), t.Type.String())
+			return nil, lexer.UnexpectedTokenErr(t, end, lexer.SymbolToken)
 		}
 	}
 	return nil, errors.New("missing ';' at the end of input section")
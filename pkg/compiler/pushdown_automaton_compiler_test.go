@@ -223,7 +223,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "Symbol", Line: 1},
 			},
 			nil,
-			"[Line 1] invalid token type, expected: StateToken or SemicolonToken, got: SymbolToken",
+			"[Line 1] expected state name or ';', got symbol",
 		},
 		{
 			"no state defined",
@@ -256,7 +256,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken, got: SymbolToken",
+			"[Line 3] expected state name, got symbol",
 		},
 		{
 			"unknown state in initial state section",
@@ -313,7 +313,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken or SemicolonToken, got: ArrowToken",
+			"[Line 3] expected state name or ';', got '>'",
 		},
 		{
 			"undefined state in accepting states section",
@@ -370,7 +370,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: LeftParenToken",
+			"[Line 5] expected symbol or ';', got '('",
 		},
 		{
 			"duplicated symbol in symbols section",
@@ -469,7 +469,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol3", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: LeftParenToken or SemicolonToken, got: SymbolToken",
+			"[Line 5] expected '(' or ';', got symbol",
 		},
 		{
 			"unfinished transition",
@@ -501,6 +501,51 @@ func TestCompilePA(t *testing.T) {
 			nil,
 			"[Line 6] unfinished transition",
 		},
+		{
+			"duplicate transition",
+			[]lexer.Token{
+				// States
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.StateToken, Value: "qState2", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				// Initial state
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				// Accepting states
+				{Type: lexer.StateToken, Value: "qState2", Line: 3},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				// Symbols
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				// Transitions
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.StackStartToken, Value: "}", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.ArrowToken, Value: ">", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState2", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.StackStartToken, Value: "}", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.ArrowToken, Value: ">", Line: 6},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+				{Type: lexer.EOFToken, Value: "", Line: 6},
+			},
+			nil,
+			"[Line 6] duplicate transition for state qState, symbol symbol1 and stack symbol }, already defined in line 5",
+		},
 		{
 			"undefined state in left side transition",
 			[]lexer.Token{
@@ -702,7 +747,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol30", Line: 6},
 			},
 			nil,
-			"[Line 6] invalid token type, expected one of: RightParenToken, CommaToken, got: SymbolToken",
+			"[Line 6] expected ')' or ',', got symbol",
 		},
 		{
 			"missing semicolon after tape section",
@@ -792,7 +837,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.StackStartToken, Value: automaton.StackStartSymbol.Name, Line: 7},
 			},
 			nil,
-			"[Line 7] invalid token type, expected: SemicolonToken or SymbolToken, got: StackStartToken",
+			"[Line 7] expected ';' or symbol, got stack start symbol '}'",
 		},
 		{
 			"missing EOF at the end of token list",
@@ -870,7 +915,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.StackStartToken, Value: "}", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: StackStartToken",
+			"[Line 5] expected symbol or ';', got stack start symbol '}'",
 		},
 		{
 			"user cannot declare InputEndToken in symbol section",
@@ -887,7 +932,7 @@ func TestCompilePA(t *testing.T) {
 				{Type: lexer.InputEndToken, Value: "{", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: InputEndToken",
+			"[Line 5] expected symbol or ';', got input end symbol '{'",
 		},
 	}
 	for _, d := range data {
@@ -909,3 +954,209 @@ func TestCompilePA(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileTestsPA(t *testing.T) {
+	tokens := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Accepting states
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Transitions
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Input
+		{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+		// Tests
+		{Type: lexer.TestsToken, Value: "tests", Line: 7},
+		{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+		{Type: lexer.RightParenToken, Value: ")", Line: 8},
+		{Type: lexer.ArrowToken, Value: ">", Line: 8},
+		{Type: lexer.RejectToken, Value: "reject", Line: 8},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 8},
+		{Type: lexer.EOFToken, Value: "", Line: 8},
+	}
+	c := NewPushdownAutomatonCompiler(tokens)
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []TestCase{
+		{
+			Line:  8,
+			Input: []string{},
+			Automaton: &automaton.PushdownAutomaton{
+				States: map[string]automaton.State{
+					"qState": {Name: "qState"},
+				},
+				Symbols: map[string]automaton.Symbol{
+					automaton.InputEndSymbol.Name:   automaton.InputEndSymbol,
+					automaton.StackStartSymbol.Name: automaton.StackStartSymbol,
+					"symbol1":                       {Name: "symbol1"},
+				},
+				CurrentState: "qState",
+				Input:        []string{automaton.InputEndSymbol.Name},
+				Stack:        []string{automaton.StackStartSymbol.Name},
+				Transitions:  automaton.PATransitionFunction{},
+			},
+			ExpectedAccepted: false,
+		},
+	}
+	if diff := cmp.Diff(expected, c.Tests()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCompileInputPA(t *testing.T) {
+	source := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Accepting states
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Transitions
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Input is omitted
+		{Type: lexer.EOFToken, Value: "", Line: 5},
+	}
+	c := NewPushdownAutomatonCompiler(source)
+	withoutInput, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.CompileInput([]lexer.Token{
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+		{Type: lexer.EOFToken, Value: "", Line: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := map[string]automaton.State{
+		"qState": {Name: "qState"},
+	}
+	symbols := map[string]automaton.Symbol{
+		automaton.InputEndSymbol.Name:   automaton.InputEndSymbol,
+		automaton.StackStartSymbol.Name: automaton.StackStartSymbol,
+		"symbol1":                       {Name: "symbol1"},
+	}
+	expectedWithoutInput := &automaton.PushdownAutomaton{
+		States:       states,
+		Symbols:      symbols,
+		CurrentState: "qState",
+		Input:        []string{automaton.InputEndSymbol.Name},
+		Stack:        []string{automaton.StackStartSymbol.Name},
+		Transitions:  automaton.PATransitionFunction{},
+	}
+	if diff := cmp.Diff(expectedWithoutInput, withoutInput); diff != "" {
+		t.Error(diff)
+	}
+	expected := &automaton.PushdownAutomaton{
+		States:       states,
+		Symbols:      symbols,
+		CurrentState: "qState",
+		Input:        []string{"symbol1", automaton.InputEndSymbol.Name},
+		Stack:        []string{automaton.StackStartSymbol.Name},
+		Transitions:  automaton.PATransitionFunction{},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCompileWildcardsPA(t *testing.T) {
+	const header = "qA;\nqA;\nqA;\n0 1;\n"
+	data := []struct {
+		name           string
+		transitions    string
+		expected       automaton.PATransitionFunction
+		expectedErrMsg string
+	}{
+		{
+			"wildcards pushing back stack symbol",
+			"(qA, *, *) > (qA, *, 0)",
+			automaton.PATransitionFunction{
+				{StateName: "qA", InputSymbolName: "0", StackSymbolName: "0"}: {StateName: "qA", StackSymbolNames: []string{"0", "0"}},
+				{StateName: "qA", InputSymbolName: "0", StackSymbolName: "1"}: {StateName: "qA", StackSymbolNames: []string{"1", "0"}},
+				{StateName: "qA", InputSymbolName: "0", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}", "0"}},
+				{StateName: "qA", InputSymbolName: "1", StackSymbolName: "0"}: {StateName: "qA", StackSymbolNames: []string{"0", "0"}},
+				{StateName: "qA", InputSymbolName: "1", StackSymbolName: "1"}: {StateName: "qA", StackSymbolNames: []string{"1", "0"}},
+				{StateName: "qA", InputSymbolName: "1", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}", "0"}},
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "0"}: {StateName: "qA", StackSymbolNames: []string{"0", "0"}},
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "1"}: {StateName: "qA", StackSymbolNames: []string{"1", "0"}},
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}", "0"}},
+			},
+			"",
+		},
+		{
+			"classes and special symbols",
+			"(qA, {, {0, 1}) > (qA)\n(qA, {0, 1}, }) > (qA, })\n(qA, {, }) > (qA, }, *)",
+			automaton.PATransitionFunction{
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "0"}: {StateName: "qA", StackSymbolNames: []string{}},
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "1"}: {StateName: "qA", StackSymbolNames: []string{}},
+				{StateName: "qA", InputSymbolName: "0", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}"}},
+				{StateName: "qA", InputSymbolName: "1", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}"}},
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{"}", "}"}},
+			},
+			"",
+		},
+		{
+			"input end symbol",
+			"(qA, {, }) > (qA)",
+			automaton.PATransitionFunction{
+				{StateName: "qA", InputSymbolName: "{", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{}},
+			},
+			"",
+		},
+		{
+			"class followed by stack start symbol",
+			"(qA, {0, 1}, }) > (qA)",
+			automaton.PATransitionFunction{
+				{StateName: "qA", InputSymbolName: "0", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{}},
+				{StateName: "qA", InputSymbolName: "1", StackSymbolName: "}"}: {StateName: "qA", StackSymbolNames: []string{}},
+			},
+			"",
+		},
+		{
+			"comma at the end of class",
+			"(qA, {0, 1, }) > (qA)",
+			nil,
+			"[Line 5, Column 13] expected symbol, got '}' ending symbol class",
+		},
+		{
+			"ambiguous wildcards",
+			"(qA, 0, *) > (qA)\n(qA, *, 0) > (qA)",
+			nil,
+			"[Line 6, Column 10] duplicate transition for state qA, symbol 0 and stack symbol 0, already defined in line 5",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			source := header + d.transitions + ";\n"
+			c := NewPushdownAutomatonCompilerFromReader(lexer.NewLexer(source))
+			result, err := c.Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(d.expected, result.(*automaton.PushdownAutomaton).Transitions); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
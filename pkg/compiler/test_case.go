@@ -0,0 +1,62 @@
+package compiler
+
+import (
+	"automata-compiler/pkg/automaton"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TestCase is a single entry from the optional tests section of the source file
+type TestCase struct {
+	// Line on which test case is defined
+	Line int
+	// Input (or initial tape for TM) provided for the test case
+	Input []string
+	// Automaton ready to be run with the test case input
+	Automaton automaton.Automaton
+	// ExpectedAccepted is the expected acceptance of the input
+	ExpectedAccepted bool
+	// ExpectedTape is the expected final tape, it's set only for TM test cases
+	ExpectedTape []string
+}
+
+// Verify returns an error describing the difference between the expected outcome and `result`
+func (tc TestCase) Verify(result automaton.AutomatonResult) error {
+	if result.Accepted() != tc.ExpectedAccepted {
+		return fmt.Errorf("expected %s, got %s", acceptanceToString(tc.ExpectedAccepted), acceptanceToString(result.Accepted()))
+	}
+	if tc.ExpectedTape == nil {
+		return nil
+	}
+	tmr, ok := result.(automaton.TuringMachineResult)
+	if !ok {
+		return fmt.Errorf("expected tape can only be checked for turing machine result")
+	}
+	finalTape := make([]string, 0, len(tmr.FinalTape))
+	for _, s := range tmr.FinalTape {
+		finalTape = append(finalTape, s.Name)
+	}
+	expected := trimTrailingBlanks(tc.ExpectedTape)
+	got := trimTrailingBlanks(finalTape)
+	if !slices.Equal(expected, got) {
+		return fmt.Errorf("expected tape: %s, got: %s", strings.Join(expected, "|"), strings.Join(got, "|"))
+	}
+	return nil
+}
+
+func acceptanceToString(accepted bool) string {
+	if accepted {
+		return "accept"
+	}
+	return "reject"
+}
+
+// trimTrailingBlanks removes all blank symbols from the end of the tape
+func trimTrailingBlanks(tape []string) []string {
+	end := len(tape)
+	for end > 0 && tape[end-1] == automaton.BlankSymbol.Name {
+		end--
+	}
+	return tape[:end]
+}
@@ -0,0 +1,58 @@
+package compiler
+
+import (
+	"automata-compiler/pkg/automaton"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	data := []struct {
+		name           string
+		testCase       TestCase
+		result         automaton.AutomatonResult
+		expectedErrMsg string
+	}{
+		{
+			"accepted as expected",
+			TestCase{ExpectedAccepted: true},
+			automaton.DeterministicFiniteAutomatonResult{FinalState: automaton.State{Name: "qState", Accepting: true}},
+			"",
+		},
+		{
+			"rejected but accept expected",
+			TestCase{ExpectedAccepted: true},
+			automaton.PushdownAutomatonResult{FinalState: automaton.State{Name: "qState"}},
+			"expected accept, got reject",
+		},
+		{
+			"same tape ignoring trailing blanks",
+			TestCase{ExpectedAccepted: true, ExpectedTape: []string{"s1", "s2", automaton.BlankSymbol.Name}},
+			automaton.TuringMachineResult{
+				FinalState: automaton.State{Name: "qState", Accepting: true},
+				FinalTape:  []automaton.Symbol{{Name: "s1"}, {Name: "s2"}},
+			},
+			"",
+		},
+		{
+			"different tape",
+			TestCase{ExpectedAccepted: true, ExpectedTape: []string{"s1", "s1"}},
+			automaton.TuringMachineResult{
+				FinalState: automaton.State{Name: "qState", Accepting: true},
+				FinalTape:  []automaton.Symbol{{Name: "s1"}, automaton.BlankSymbol},
+			},
+			"expected tape: s1|s1, got: s1",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			err := d.testCase.Verify(d.result)
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
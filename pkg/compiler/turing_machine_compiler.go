@@ -5,6 +5,7 @@ import (
 	"automata-compiler/pkg/lexer"
 	"errors"
 	"fmt"
+	"slices"
 )
 
 type TuringMachineCompiler struct {
@@ -12,44 +13,121 @@ type TuringMachineCompiler struct {
 }
 
 func NewTuringMachineCompiler(tokens []lexer.Token) *TuringMachineCompiler {
-	return &TuringMachineCompiler{BaseCompiler: newBaseCompiler(tokens)}
+	return &TuringMachineCompiler{BaseCompiler: newBaseCompiler(tokens, tmSpecialSymbols, reservedLetters...)}
+}
+
+// NewTuringMachineCompilerFromReader creates compiler reading tokens one by one from `r`
+func NewTuringMachineCompilerFromReader(r lexer.TokenReader) *TuringMachineCompiler {
+	return &TuringMachineCompiler{BaseCompiler: newBaseCompilerFromReader(r, tmSpecialSymbols, reservedLetters...)}
 }
 
 func (tm *TuringMachineCompiler) Compile() (automaton.Automaton, error) {
+	// Errors are reported and processing continues with the next section, so all errors are returned at once
+	tm.processHeader("TM")
+	tm.processSections()
 	states, err := tm.processStates()
-	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
-	}
+	tm.recoverSection(err)
 	initialState, err := tm.processInitialState(states)
-	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
-	}
+	tm.recoverSection(err)
 	err = tm.processAcceptingStates(states)
-	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
-	}
+	tm.recoverSection(err)
 	specialSymbols := tm.getSpecialSymbols()
 	symbols, err := tm.processSymbols(specialSymbols)
-	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
-	}
+	tm.recoverSection(err)
 	tf, err := tm.processTransitions(states, symbols)
-	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
+	tm.recoverSection(err)
+	newAutomaton := func(input []string) automaton.Automaton {
+		return newTuringMachine(states, symbols, initialState, tf, input)
+	}
+	var initialTape []string
+	if !tm.isInputOmitted() {
+		initialTape, err = tm.processTape(symbols, lexer.SemicolonToken)
+		tm.recoverSection(err)
+	}
+	err = tm.processTests(func(line int) (TestCase, error) {
+		return tm.processTestCase(line, symbols, newAutomaton)
+	})
+	tm.recoverSection(err)
+	if err := tm.finish(); err != nil {
+		return nil, err
+	}
+	tm.symbols = symbols
+	tm.newAutomaton = newAutomaton
+	return newAutomaton(initialTape), nil
+}
+
+func (tm *TuringMachineCompiler) CompileInput(tokens []lexer.Token) (automaton.Automaton, error) {
+	return tm.compileInput(NewTuringMachineCompiler(tokens))
+}
+
+func (tm *TuringMachineCompiler) CompileInputFromReader(r lexer.TokenReader) (automaton.Automaton, error) {
+	return tm.compileInput(NewTuringMachineCompilerFromReader(r))
+}
+
+// compileInput processes input with `ic`, compiler created for the input tokens
+func (tm *TuringMachineCompiler) compileInput(ic *TuringMachineCompiler) (automaton.Automaton, error) {
+	if err := tm.checkCompiled(); err != nil {
+		return nil, err
+	}
+	input, err := ic.processTape(tm.symbols, lexer.EOFToken)
+	if ic.lexErr != nil {
+		return nil, ic.lexErr
 	}
-	initialTape, err := tm.processTape(symbols)
 	if err != nil {
-		return nil, tm.addLinePrefixForErrPrevToken(err)
+		return nil, ic.addLinePrefixForErrPrevToken(err)
 	}
-	err = tm.checkForCorrectEndingSequnce()
+	if len(ic.errs) > 0 {
+		return nil, ic.errs[0]
+	}
+	return tm.newAutomaton(input), nil
+}
+
+func newTuringMachine(states map[string]automaton.State, symbols map[string]automaton.Symbol, initialState string, tf automaton.TMTransitionFunction, tape []string) *automaton.TuringMachine {
+	if len(tape) == 0 {
+		tape = append(tape, automaton.BlankSymbol.Name)
+	}
+	return &automaton.TuringMachine{States: states, CurrentState: initialState, Symbols: symbols, Transitions: tf, Tape: tape, TapeIt: 0}
+}
+
+func (tm *TuringMachineCompiler) processTestCase(line int, symbols map[string]automaton.Symbol, newAutomaton func(input []string) automaton.Automaton) (TestCase, error) {
+	// Each test case is as follows:
+	// (symbol1 symbol2 ...) > (symbol1 symbol2 ...)
+	// where right side is the expected final tape
+	// At this point '(' has already been processed
+	const atEndErrMsg = "unfinished test case"
+	input, err := tm.processTestInput(symbols, lexer.SymbolToken, lexer.BlankSymbolToken)
 	if err != nil {
-		// It's more lexer error than user provided source,
-		// so we don't include line here
-		return nil, err
+		return TestCase{}, err
+	}
+	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.LeftParenToken); err != nil {
+		return TestCase{}, err
 	}
-	return &automaton.TuringMachine{States: states, CurrentState: initialState, Symbols: symbols, Transitions: tf, Tape: initialTape, TapeIt: 0}, nil
+	expectedTape := make([]string, 0)
+	for tm.peek().Type != lexer.RightParenToken {
+		// RightParenToken is passed only for better error message
+		t, err := tm.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken, lexer.RightParenToken)
+		if err != nil {
+			return TestCase{}, err
+		}
+		if _, ok := symbols[t.Value]; !ok {
+			return TestCase{}, fmt.Errorf("invalid symbol %s in test case expected tape, each symbol must be defined in symbols section", t.Value)
+		}
+		expectedTape = append(expectedTape, t.Value)
+	}
+	// Consume ')'
+	tm.advance()
+	return TestCase{
+		Line:             line,
+		Input:            input,
+		Automaton:        newAutomaton(slices.Clone(input)),
+		ExpectedAccepted: true,
+		ExpectedTape:     expectedTape,
+	}, nil
 }
 
+// tmSpecialSymbols are names of symbols returned by `getSpecialSymbols`
+var tmSpecialSymbols = []string{automaton.BlankSymbol.Name}
+
 func (tm TuringMachineCompiler) getSpecialSymbols() map[string]automaton.Symbol {
 	symbols := make(map[string]automaton.Symbol)
 	symbols[automaton.BlankSymbol.Name] = automaton.BlankSymbol
@@ -64,110 +142,136 @@ func (tm *TuringMachineCompiler) processTransitions(states map[string]automaton.
 		case lexer.SemicolonToken:
 			return tf, nil
 		case lexer.LeftParenToken:
-			err := tm.processSingleTransition(states, symbols, tf)
+			err := tm.processSingleTransition(t.Line, states, symbols, tf)
 			if err != nil {
-				return nil, err
+				tm.recoverItem(err)
 			}
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s or %s, got: %s", lexer.LeftParenToken.String(), lexer.SemicolonToken.String(), t.Type.String())
+			tm.recoverItem(lexer.UnexpectedTokenErr(t, lexer.LeftParenToken, lexer.SemicolonToken))
 		}
 	}
-	return nil, errors.New("missing ';' at the end of transitions section")
+	return tf, errors.New("missing ';' at the end of transitions section")
 }
 
-func (tm *TuringMachineCompiler) processSingleTransition(states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.TMTransitionFunction) error {
+func (tm *TuringMachineCompiler) processSingleTransition(line int, states map[string]automaton.State, symbols map[string]automaton.Symbol, tf automaton.TMTransitionFunction) error {
 	// Each transition is as follows:
 	// (state, symbol) > (state, symbol, movement)
+	// where symbol on the left side can be also a class of symbols, e.g. {symbol1, symbol2}, or * matching any
+	// symbol, and * on the right side writes back the symbol that was read
 	// At this point '(' has already been processed
 	const atEndErrMsg = "unfinished transition"
-	leftSide, err := tm.processTransitionLeftSide(states, symbols, atEndErrMsg)
+	leftSides, rank, err := tm.processTransitionLeftSide(states, symbols, atEndErrMsg)
 	if err != nil {
 		return err
 	}
+	for _, leftSide := range leftSides {
+		if prevLine, ok := tm.duplicateTransition(leftSide, rank); ok {
+			return fmt.Errorf("duplicate transition for state %s and symbol %s, already defined in line %d", leftSide.StateName, leftSide.SymbolName, prevLine)
+		}
+	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.ArrowToken); err != nil {
 		return err
 	}
-	rightSide, err := tm.processTransitionRightSide(states, symbols, atEndErrMsg)
+	rightSide, writeBack, err := tm.processTransitionRightSide(states, symbols, atEndErrMsg)
 	if err != nil {
 		return err
 	}
-	tf[leftSide] = rightSide
+	for _, leftSide := range leftSides {
+		if !tm.defineTransition(leftSide, rank, line) {
+			continue
+		}
+		value := rightSide
+		if writeBack {
+			value.SymbolName = leftSide.SymbolName
+		}
+		tf[leftSide] = value
+	}
 	return nil
 }
 
-func (tm *TuringMachineCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.TMTransitionKey, error) {
-	var zero automaton.TMTransitionKey
+// processTransitionLeftSide returns keys of all transitions matched by the left side and its rank
+func (tm *TuringMachineCompiler) processTransitionLeftSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) ([]automaton.TMTransitionKey, int, error) {
 	state, err := tm.consumeTokenWithType(atEndErrMsg, lexer.StateToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
 	if _, ok := states[state.Value]; !ok {
-		return zero, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
+		return nil, 0, fmt.Errorf("undefined state %s used in transition function left side", state.Value)
 	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	symbol, err := tm.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken)
+	symbol, err := tm.processSymbolPattern(wildcardSymbols(symbols), atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken)
 	if err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	if _, ok := symbols[symbol.Value]; !ok {
-		return zero, fmt.Errorf("undefined symbol %s used in transition function left side", symbol.Value)
+	keys := make([]automaton.TMTransitionKey, 0, len(symbol.symbols))
+	for _, name := range symbol.symbols {
+		if _, ok := symbols[name]; !ok {
+			return nil, 0, fmt.Errorf("undefined symbol %s used in transition function left side", name)
+		}
+		keys = append(keys, automaton.TMTransitionKey{StateName: state.Value, SymbolName: name})
 	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.RightParenToken); err != nil {
-		return zero, err
+		return nil, 0, err
 	}
-	return automaton.TMTransitionKey{StateName: state.Value, SymbolName: symbol.Value}, nil
+	return keys, symbol.rank, nil
 }
 
-func (tm *TuringMachineCompiler) processTransitionRightSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.TMTransitionValue, error) {
+// processTransitionRightSide returns the right side of the transition and whether the written symbol is `*`,
+// in which case the symbol that was read is written back and the symbol of the returned value is empty
+func (tm *TuringMachineCompiler) processTransitionRightSide(states map[string]automaton.State, symbols map[string]automaton.Symbol, atEndErrMsg string) (automaton.TMTransitionValue, bool, error) {
 	var zero automaton.TMTransitionValue
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.LeftParenToken); err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	state, err := tm.consumeTokenWithType(atEndErrMsg, lexer.StateToken)
 	if err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	if _, ok := states[state.Value]; !ok {
-		return zero, fmt.Errorf("undefined state %s used in transition function right side", state.Value)
+		return zero, false, fmt.Errorf("undefined state %s used in transition function right side", state.Value)
 	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return zero, false, err
 	}
-	symbol, err := tm.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken)
+	symbol, err := tm.consumeTokenWithType(atEndErrMsg, lexer.SymbolToken, lexer.BlankSymbolToken, lexer.WildcardToken)
 	if err != nil {
-		return zero, err
+		return zero, false, err
 	}
-	if _, ok := symbols[symbol.Value]; !ok {
-		return zero, fmt.Errorf("undefined symbol %s used in transition function right side", symbol.Value)
+	writeBack := symbol.Type == lexer.WildcardToken
+	if _, ok := symbols[symbol.Value]; !ok && !writeBack {
+		return zero, false, fmt.Errorf("undefined symbol %s used in transition function right side", symbol.Value)
 	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.CommaToken); err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	move, err := tm.consumeTokenWithType(atEndErrMsg, lexer.MoveLeftToken, lexer.MoveRightToken)
 	if err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	if _, err := tm.consumeTokenWithType(atEndErrMsg, lexer.RightParenToken); err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	moveValue := automaton.TapeMoveLeft
 	if move.Type == lexer.MoveRightToken {
 		moveValue = automaton.TapeMoveRight
 	}
-	return automaton.TMTransitionValue{StateName: state.Value, SymbolName: symbol.Value, Move: moveValue}, nil
+	value := automaton.TMTransitionValue{StateName: state.Value, Move: moveValue}
+	if !writeBack {
+		value.SymbolName = symbol.Value
+	}
+	return value, writeBack, nil
 }
 
-func (tm *TuringMachineCompiler) processTape(symbols map[string]automaton.Symbol) ([]string, error) {
+// processTape processes symbols until `end` token is reached, `end` is either ';' for the section in the source or EOF
+// for the input provided separately
+func (tm *TuringMachineCompiler) processTape(symbols map[string]automaton.Symbol, end lexer.TokenType) ([]string, error) {
 	tape := make([]string, 0)
 	for !tm.isAtEnd() {
 		t := tm.advance()
 		switch t.Type {
-		case lexer.SemicolonToken:
-			if len(tape) == 0 {
-				tape = append(tape, automaton.BlankSymbol.Name)
-			}
+		case end:
 			return tape, nil
 		case lexer.SymbolToken:
 			if _, ok := symbols[t.Value]; !ok {
@@ -177,7 +281,7 @@ func (tm *TuringMachineCompiler) processTape(symbols map[string]automaton.Symbol
 		case lexer.BlankSymbolToken:
 			tape = append(tape, t.Value)
 		default:
-			return nil, fmt.Errorf("invalid token type, expected: %s, %s or %s, got: %s", lexer.SemicolonToken.String(), lexer.SymbolToken.String(), lexer.BlankSymbolToken.String(), t.Type.String())
+			return nil, lexer.UnexpectedTokenErr(t, end, lexer.SymbolToken, lexer.BlankSymbolToken)
 		}
 	}
 	return nil, errors.New("missing ';' at the end of tape section")
@@ -3,7 +3,9 @@ package compiler
 import (
 	"automata-compiler/pkg/automaton"
 	"automata-compiler/pkg/lexer"
+	"errors"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -168,7 +170,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "Symbol", Line: 1},
 			},
 			nil,
-			"[Line 1] invalid token type, expected: StateToken or SemicolonToken, got: SymbolToken",
+			"[Line 1] expected state name or ';', got symbol",
 		},
 		{
 			"no state defined",
@@ -201,7 +203,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken, got: SymbolToken",
+			"[Line 3] expected state name, got symbol",
 		},
 		{
 			"unknown state in initial state section",
@@ -258,7 +260,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
 			},
 			nil,
-			"[Line 3] invalid token type, expected: StateToken or SemicolonToken, got: ArrowToken",
+			"[Line 3] expected state name or ';', got '>'",
 		},
 		{
 			"undefined state in accepting states section",
@@ -315,7 +317,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: LeftParenToken",
+			"[Line 5] expected symbol or ';', got '('",
 		},
 		{
 			"duplicated symbol in symbols section",
@@ -416,7 +418,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SymbolToken, Value: "symbol3", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: LeftParenToken or SemicolonToken, got: SymbolToken",
+			"[Line 5] expected '(' or ';', got symbol",
 		},
 		{
 			"unfinished transition",
@@ -448,6 +450,55 @@ func TestCompileTM(t *testing.T) {
 			nil,
 			"[Line 6] unfinished transition",
 		},
+		{
+			"duplicate transition",
+			[]lexer.Token{
+				// States
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.StateToken, Value: "qState2", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				// Initial state
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				// Accepting states
+				{Type: lexer.StateToken, Value: "qState2", Line: 3},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				// Symbols
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				// Transitions
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.ArrowToken, Value: ">", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 5},
+				{Type: lexer.StateToken, Value: "qState2", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 5},
+				{Type: lexer.CommaToken, Value: ",", Line: 5},
+				{Type: lexer.MoveRightToken, Value: "R", Line: 5},
+				{Type: lexer.RightParenToken, Value: ")", Line: 5},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.ArrowToken, Value: ">", Line: 6},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+				{Type: lexer.StateToken, Value: "qState", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 6},
+				{Type: lexer.CommaToken, Value: ",", Line: 6},
+				{Type: lexer.MoveRightToken, Value: "R", Line: 6},
+				{Type: lexer.RightParenToken, Value: ")", Line: 6},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+				{Type: lexer.EOFToken, Value: "", Line: 6},
+			},
+			nil,
+			"[Line 6] duplicate transition for state qState and symbol symbol1, already defined in line 5",
+		},
 		{
 			"undefined state in left side transition",
 			[]lexer.Token{
@@ -620,7 +671,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.ArrowToken, Value: ">", Line: 6},
 			},
 			nil,
-			"[Line 6] invalid token type, expected one of: MoveLeftToken, MoveRightToken, got: ArrowToken",
+			"[Line 6] expected move 'L' or move 'R', got '>'",
 		},
 		{
 			"missing semicolon after tape section",
@@ -710,7 +761,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.ArrowToken, Value: ">", Line: 7},
 			},
 			nil,
-			"[Line 7] invalid token type, expected: SemicolonToken, SymbolToken or BlankSymbolToken, got: ArrowToken",
+			"[Line 7] expected ';', symbol or blank symbol 'B', got '>'",
 		},
 		{
 			"missing EOF at the end of token list",
@@ -797,7 +848,7 @@ func TestCompileTM(t *testing.T) {
 				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
 			},
 			nil,
-			"[Line 5] invalid token type, expected: SymbolToken or SemicolonToken, got: BlankSymbolToken",
+			"[Line 5] expected symbol or ';', got blank symbol 'B'",
 		},
 		{
 			"empty tape should contain one blank symbol",
@@ -904,3 +955,532 @@ func TestCompileTM(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileTestsTM(t *testing.T) {
+	data := []struct {
+		name           string
+		testsTokens    []lexer.Token
+		expected       []TestCase
+		expectedErrMsg string
+	}{
+		{
+			"expected tape",
+			[]lexer.Token{
+				{Type: lexer.TestsToken, Value: "tests", Line: 7},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 8},
+				{Type: lexer.BlankSymbolToken, Value: "B", Line: 8},
+				{Type: lexer.RightParenToken, Value: ")", Line: 8},
+				{Type: lexer.ArrowToken, Value: ">", Line: 8},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 8},
+				{Type: lexer.RightParenToken, Value: ")", Line: 8},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 8},
+				{Type: lexer.EOFToken, Value: "", Line: 8},
+			},
+			[]TestCase{
+				{
+					Line:  8,
+					Input: []string{"symbol1", automaton.BlankSymbol.Name},
+					Automaton: &automaton.TuringMachine{
+						States: map[string]automaton.State{
+							"qState": {Name: "qState", Accepting: true},
+						},
+						Symbols: map[string]automaton.Symbol{
+							automaton.BlankSymbol.Name: automaton.BlankSymbol,
+							"symbol1":                  {Name: "symbol1"},
+						},
+						CurrentState: "qState",
+						Tape:         []string{"symbol1", automaton.BlankSymbol.Name},
+						Transitions:  automaton.TMTransitionFunction{},
+					},
+					ExpectedAccepted: true,
+					ExpectedTape:     []string{"symbol1"},
+				},
+			},
+			"",
+		},
+		{
+			"undefined symbol in input",
+			[]lexer.Token{
+				{Type: lexer.TestsToken, Value: "tests", Line: 7},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+				{Type: lexer.SymbolToken, Value: "symbol2", Line: 8},
+				{Type: lexer.RightParenToken, Value: ")", Line: 8},
+				{Type: lexer.ArrowToken, Value: ">", Line: 8},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+				{Type: lexer.RightParenToken, Value: ")", Line: 8},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 8},
+				{Type: lexer.EOFToken, Value: "", Line: 8},
+			},
+			nil,
+			"[Line 8] invalid symbol symbol2 in test case input, each symbol must be defined in symbols section",
+		},
+		{
+			"acceptance instead of tape",
+			[]lexer.Token{
+				{Type: lexer.TestsToken, Value: "tests", Line: 7},
+				{Type: lexer.LeftParenToken, Value: "(", Line: 8},
+				{Type: lexer.RightParenToken, Value: ")", Line: 8},
+				{Type: lexer.ArrowToken, Value: ">", Line: 8},
+				{Type: lexer.AcceptToken, Value: "accept", Line: 8},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 8},
+				{Type: lexer.EOFToken, Value: "", Line: 8},
+			},
+			nil,
+			"[Line 8] expected '(', got 'accept'",
+		},
+		{
+			"missing semicolon",
+			[]lexer.Token{
+				{Type: lexer.TestsToken, Value: "tests", Line: 7},
+				{Type: lexer.EOFToken, Value: "", Line: 7},
+			},
+			nil,
+			"[Line 7] expected '(' or ';', got end of source",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			tokens := []lexer.Token{
+				// States
+				{Type: lexer.StateToken, Value: "qState", Line: 1},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+				// Initial state
+				{Type: lexer.StateToken, Value: "qState", Line: 2},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+				// Accepting states
+				{Type: lexer.StateToken, Value: "qState", Line: 3},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+				// Symbols
+				{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+				{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+				// Transitions
+				{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+				// Initial tape
+				{Type: lexer.SemicolonToken, Value: ";", Line: 6},
+			}
+			tokens = append(tokens, d.testsTokens...)
+			c := NewTuringMachineCompiler(tokens)
+			_, err := c.Compile()
+			if diff := cmp.Diff(d.expected, c.Tests()); diff != "" {
+				t.Error(diff)
+			}
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestCompileInputTM(t *testing.T) {
+	source := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 1},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Accepting states
+		{Type: lexer.StateToken, Value: "qState", Line: 3},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Transitions
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Initial tape is omitted
+		{Type: lexer.EOFToken, Value: "", Line: 5},
+	}
+	c := NewTuringMachineCompiler(source)
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.CompileInput([]lexer.Token{
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+		{Type: lexer.BlankSymbolToken, Value: "B", Line: 1},
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 1},
+		{Type: lexer.EOFToken, Value: "", Line: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &automaton.TuringMachine{
+		States: map[string]automaton.State{
+			"qState": {Name: "qState", Accepting: true},
+		},
+		Symbols: map[string]automaton.Symbol{
+			automaton.BlankSymbol.Name: automaton.BlankSymbol,
+			"symbol1":                  {Name: "symbol1"},
+		},
+		CurrentState: "qState",
+		Tape:         []string{"symbol1", automaton.BlankSymbol.Name, "symbol1"},
+		Transitions:  automaton.TMTransitionFunction{},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSourceLinesTM(t *testing.T) {
+	source := []lexer.Token{
+		// States
+		{Type: lexer.StateToken, Value: "qState", Line: 1},
+		{Type: lexer.StateToken, Value: "qState2", Line: 2},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 2},
+		// Initial state
+		{Type: lexer.StateToken, Value: "qState", Line: 3},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 3},
+		// Accepting states
+		{Type: lexer.StateToken, Value: "qState2", Line: 4},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 4},
+		// Symbols
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 5},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 5},
+		// Transitions
+		{Type: lexer.LeftParenToken, Value: "(", Line: 6},
+		{Type: lexer.StateToken, Value: "qState", Line: 6},
+		{Type: lexer.CommaToken, Value: ",", Line: 6},
+		{Type: lexer.SymbolToken, Value: "symbol1", Line: 6},
+		{Type: lexer.RightParenToken, Value: ")", Line: 6},
+		{Type: lexer.ArrowToken, Value: ">", Line: 6},
+		{Type: lexer.LeftParenToken, Value: "(", Line: 7},
+		{Type: lexer.StateToken, Value: "qState2", Line: 7},
+		{Type: lexer.CommaToken, Value: ",", Line: 7},
+		{Type: lexer.BlankSymbolToken, Value: "B", Line: 7},
+		{Type: lexer.CommaToken, Value: ",", Line: 7},
+		{Type: lexer.MoveRightToken, Value: "R", Line: 7},
+		{Type: lexer.RightParenToken, Value: ")", Line: 7},
+		{Type: lexer.SemicolonToken, Value: ";", Line: 7},
+		{Type: lexer.EOFToken, Value: "", Line: 7},
+	}
+	c := NewTuringMachineCompiler(source)
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	expected := SourceLines{
+		States:  map[string]int{"qState": 1, "qState2": 2},
+		Symbols: map[string]int{"symbol1": 5},
+		Transitions: map[any]int{
+			automaton.TMTransitionKey{StateName: "qState", SymbolName: "symbol1"}: 6,
+		},
+	}
+	if diff := cmp.Diff(expected, c.SourceLines()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCompileErrorsTM(t *testing.T) {
+	data := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{
+			"errors in many sections",
+			"qA qA;\nqB;\nqA;\n0;\n" +
+				"(qA, 0) > (qA, 0, R)\n" +
+				"(qC, 0) > (qA, 0, R)\n" +
+				"(qA, 1) > (qA, 0, R)\n" +
+				"(qA, 0 > (qA, 0, R)\n" +
+				";\n0;\n",
+			[]string{
+				"[Line 1, Column 4] state qA already declared, each state must have unique name",
+				"[Line 2, Column 1] invalid initial state, state qB was not declared in states list",
+				"[Line 6, Column 2] undefined state qC used in transition function left side",
+				"[Line 7, Column 6] undefined symbol 1 used in transition function left side",
+				"[Line 8, Column 8] expected ')', got '>'",
+			},
+		},
+		{
+			"errors in transitions and tests",
+			"qA qB;\nqA;\nqB;\n0 1;\n" +
+				"(qA, 0) > (qB, 0, R)\n" +
+				"(qA, 0) > (qB, 0, R)\n" +
+				"(qA, 1) > (qB, 2, R);\n" +
+				"0 1;\n" +
+				"tests\n" +
+				"(0 2) > (0)\n" +
+				"(1) > accept\n" +
+				"(0) > (0);\n",
+			[]string{
+				"[Line 6, Column 7] duplicate transition for state qA and symbol 0, already defined in line 5",
+				"[Line 7, Column 16] undefined symbol 2 used in transition function right side",
+				"[Line 10, Column 4] invalid symbol 2 in test case input, each symbol must be defined in symbols section",
+				"[Line 11, Column 7] expected '(', got 'accept'",
+			},
+		},
+		{
+			"unfinished source reported once",
+			"qA;\nqA;\nqA;\n0;\n(qA, 0) > (qA, 0, R)\n(qA\n",
+			[]string{
+				"[Line 7, Column 1] expected ',', got end of source",
+			},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			tokens, err := lexer.NewLexer(d.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = NewTuringMachineCompiler(tokens).Compile()
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			result := []string{err.Error()}
+			var errs Errors
+			if errors.As(err, &errs) {
+				result = make([]string, 0, len(errs))
+				for _, e := range errs {
+					result = append(result, e.Error())
+				}
+			}
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCompileErrorsBeforeLexErrorTM(t *testing.T) {
+	source := "qA qA;\nqB;\nqA;\n0;\n(qA, 1) > (qA, 0, R)\n(qA, |) > (qA, 0, R);\n0;\n"
+	_, err := NewTuringMachineCompilerFromReader(lexer.NewLexer(source)).Compile()
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got: %v", err)
+	}
+	result := make([]string, 0, len(errs))
+	for _, e := range errs {
+		result = append(result, e.Error())
+	}
+	expected := []string{
+		"[Line 1, Column 4] state qA already declared, each state must have unique name",
+		"[Line 2, Column 1] invalid initial state, state qB was not declared in states list",
+		"[Line 5, Column 6] undefined symbol 1 used in transition function left side",
+		"[Line 6, Column 6] unknown symbol |",
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+	var lexErr lexer.LexError
+	if !errors.As(err, &lexErr) {
+		t.Errorf("expected LexError in errors, got: %v", err)
+	}
+}
+
+func TestCompileErrorPositionTM(t *testing.T) {
+	tokens, err := lexer.NewLexer("qA;\nqA;\nqA;\n0;\n(qA, 0 > (qA, 0, R);\n;").ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewTuringMachineCompiler(tokens).Compile()
+	var compileErr CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("expected CompileError, got: %v", err)
+	}
+	if compileErr.Line != 5 || compileErr.Column != 8 {
+		t.Errorf("invalid position, expected: 5:8, got: %d:%d", compileErr.Line, compileErr.Column)
+	}
+	if diff := cmp.Diff(lexer.Span{Start: 22, End: 23}, compileErr.Span); diff != "" {
+		t.Error(diff)
+	}
+	expectedErrMsg := "expected ')', got '>'"
+	if compileErr.Err.Error() != expectedErrMsg {
+		t.Errorf("invalid error message, expected: %s, got: %s", expectedErrMsg, compileErr.Err.Error())
+	}
+}
+
+func TestCompileFromReaderTM(t *testing.T) {
+	data := []struct {
+		name   string
+		source string
+	}{
+		{
+			"valid source with tests",
+			"qA qB;\nqA;\nqB;\n0 1;\n" +
+				"(qA, 0) > (qA, 1, R)\n" +
+				"(qA, B) > (qB, B, L);\n" +
+				"0 0;\n" +
+				"tests\n" +
+				"(0) > (1 B);\n",
+		},
+		{
+			"input omitted",
+			"qA;\nqA;\nqA;\n0;\n;\n",
+		},
+		{
+			"errors with recovery",
+			"qA qA;\nqB;\nqA;\n0;\n" +
+				"(qC, 0) > (qA, 0, R)\n" +
+				"(qA, 1) > (qA, 0, R)\n" +
+				"(qA, 0 > (qA, 0, R)\n" +
+				";\n0;\n",
+		},
+		{
+			"lexing error",
+			"qA;\nqA;\nqA;\n0;\n(qA, |) > (qA, 0, R);\n",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			var expected automaton.Automaton
+			var expectedTests []TestCase
+			var expectedLines SourceLines
+			tokens, expectedErr := lexer.NewLexer(d.source).ScanTokens()
+			if expectedErr == nil {
+				c := NewTuringMachineCompiler(tokens)
+				expected, expectedErr = c.Compile()
+				expectedTests, expectedLines = c.Tests(), c.SourceLines()
+			}
+			c := NewTuringMachineCompilerFromReader(lexer.NewReaderLexer(strings.NewReader(d.source)))
+			result, err := c.Compile()
+			if diff := cmp.Diff(expected, result); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(expectedTests, c.Tests()); diff != "" {
+				t.Error(diff)
+			}
+			if expectedErr == nil {
+				if diff := cmp.Diff(expectedLines, c.SourceLines()); diff != "" {
+					t.Error(diff)
+				}
+			}
+			var errMsg, expectedErrMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if expectedErr != nil {
+				expectedErrMsg = expectedErr.Error()
+			}
+			if errMsg != expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestCompileInputFromReaderTM(t *testing.T) {
+	tokens, err := lexer.NewLexer("qA;\nqA;\nqA;\n0;\n;\n").ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewTuringMachineCompiler(tokens)
+	if _, err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		name           string
+		input          string
+		expectedTape   []string
+		expectedErrMsg string
+	}{
+		{"valid input", "0 B\n0", []string{"0", "B", "0"}, ""},
+		{"undefined symbol", "0 1", nil, "[Line 1, Column 3] invalid symbol 1 in initial tape, each symbol must be defined in symbols section"},
+		{"lexing error", "0 |", nil, "[Line 1, Column 3] unknown symbol |"},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			a, err := c.CompileInputFromReader(lexer.NewReaderLexer(strings.NewReader(d.input)))
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			var tape []string
+			if tm, ok := a.(*automaton.TuringMachine); ok {
+				tape = tm.Tape
+			}
+			if diff := cmp.Diff(d.expectedTape, tape); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCompileWildcardsTM(t *testing.T) {
+	const header = "qA qB;\nqA;\nqB;\n0 1;\n"
+	data := []struct {
+		name           string
+		transitions    string
+		expected       automaton.TMTransitionFunction
+		expectedErrMsg string
+	}{
+		{
+			"wildcard writing back read symbol",
+			"(qA, *) > (qA, *, R)",
+			automaton.TMTransitionFunction{
+				{StateName: "qA", SymbolName: "0"}: {StateName: "qA", SymbolName: "0", Move: automaton.TapeMoveRight},
+				{StateName: "qA", SymbolName: "1"}: {StateName: "qA", SymbolName: "1", Move: automaton.TapeMoveRight},
+				{StateName: "qA", SymbolName: "B"}: {StateName: "qA", SymbolName: "B", Move: automaton.TapeMoveRight},
+			},
+			"",
+		},
+		{
+			"explicit transition takes precedence regardless of order",
+			"(qA, 0) > (qB, 1, L)\n(qA, *) > (qA, 0, R)\n(qA, {1, B}) > (qB, *, R)",
+			automaton.TMTransitionFunction{
+				{StateName: "qA", SymbolName: "0"}: {StateName: "qB", SymbolName: "1", Move: automaton.TapeMoveLeft},
+				{StateName: "qA", SymbolName: "1"}: {StateName: "qB", SymbolName: "1", Move: automaton.TapeMoveRight},
+				{StateName: "qA", SymbolName: "B"}: {StateName: "qB", SymbolName: "B", Move: automaton.TapeMoveRight},
+			},
+			"",
+		},
+		{
+			"overlapping classes",
+			"(qA, {0, 1}) > (qA, *, R)\n(qA, {1, B}) > (qB, *, R)",
+			nil,
+			"[Line 6, Column 12] duplicate transition for state qA and symbol 1, already defined in line 5",
+		},
+		{
+			"two wildcards",
+			"(qA, *) > (qA, *, R)\n(qA, *) > (qB, *, R)",
+			nil,
+			"[Line 6, Column 7] duplicate transition for state qA and symbol 0, already defined in line 5",
+		},
+		{
+			"undefined symbol in class",
+			"(qA, {0, 2}) > (qA, *, R)",
+			nil,
+			"[Line 5, Column 11] undefined symbol 2 used in transition function left side",
+		},
+		{
+			"repeated symbol in class",
+			"(qA, {0, 0}) > (qA, *, R)",
+			nil,
+			"[Line 5, Column 10] symbol 0 used more than once in symbol class",
+		},
+		{
+			"unclosed class",
+			"(qA, {0 1) > (qA, *, R)",
+			nil,
+			"[Line 5, Column 9] expected ',' or '}' in symbol class, got symbol",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			source := header + d.transitions + ";\n"
+			c := NewTuringMachineCompilerFromReader(lexer.NewLexer(source))
+			result, err := c.Compile()
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(d.expected, result.(*automaton.TuringMachine).Transitions); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Graph is a representation of the automaton state diagram, independent of the output format
+type Graph struct {
+	// States sorted by name
+	States       []automaton.State
+	InitialState string
+	// Edges sorted by source and target state, there is at most one edge between any two states
+	Edges []Edge
+}
+
+// Edge groups all transitions between two states
+type Edge struct {
+	From string
+	To   string
+	// Labels of the transitions, one per transition, sorted
+	Labels []string
+}
+
+// Label returns labels of all transitions merged into one, separated by `sep`
+func (e Edge) Label(sep string) string {
+	return strings.Join(e.Labels, sep)
+}
+
+// NewGraph creates graph from the compiled automaton, initial state is the one automaton is currently in
+func NewGraph(a automaton.Automaton) (Graph, error) {
+	switch v := a.(type) {
+	case *automaton.DeterministicFiniteAutomaton:
+		edges := make(map[edgeKey][]string)
+		for key, val := range v.Transitions {
+			ek := edgeKey{from: key.StateName, to: val.StateName}
+			edges[ek] = append(edges[ek], key.SymbolName)
+		}
+		return newGraph(v.States, v.CurrentState, edges), nil
+	case *automaton.PushdownAutomaton:
+		edges := make(map[edgeKey][]string)
+		for key, val := range v.Transitions {
+			ek := edgeKey{from: key.StateName, to: val.StateName}
+			edges[ek] = append(edges[ek], PATransitionLabel(key, val))
+		}
+		return newGraph(v.States, v.CurrentState, edges), nil
+	case *automaton.TuringMachine:
+		edges := make(map[edgeKey][]string)
+		for key, val := range v.Transitions {
+			ek := edgeKey{from: key.StateName, to: val.StateName}
+			edges[ek] = append(edges[ek], TMTransitionLabel(key, val))
+		}
+		return newGraph(v.States, v.CurrentState, edges), nil
+	default:
+		return Graph{}, fmt.Errorf("unsupported automaton type: %T", a)
+	}
+}
+
+// PATransitionLabel returns label of PA transition in form `a, X / YZ`, where `a` is the input symbol,
+// `X` is the symbol from the top of the stack and `YZ` are symbols pushed onto the stack
+func PATransitionLabel(key automaton.PATransitionKey, val automaton.PATransitionValue) string {
+	return fmt.Sprintf("%s, %s / %s", key.InputSymbolName, key.StackSymbolName, stackSymbolsToString(val.StackSymbolNames))
+}
+
+// TMTransitionLabel returns label of TM transition in form `a → b, R`
+func TMTransitionLabel(key automaton.TMTransitionKey, val automaton.TMTransitionValue) string {
+	return fmt.Sprintf("%s → %s, %s", key.SymbolName, val.SymbolName, moveToString(val.Move))
+}
+
+// stackSymbolsToString concatenates pushed symbols, they are separated with spaces only if any of them
+// is longer than one character, so the result stays unambiguous
+func stackSymbolsToString(symbols []string) string {
+	if len(symbols) == 0 {
+		return "ε"
+	}
+	sep := ""
+	for _, s := range symbols {
+		if utf8.RuneCountInString(s) > 1 {
+			sep = " "
+			break
+		}
+	}
+	return strings.Join(symbols, sep)
+}
+
+func moveToString(move automaton.TapeMoveType) string {
+	if move == automaton.TapeMoveLeft {
+		return "L"
+	}
+	return "R"
+}
+
+type edgeKey struct {
+	from string
+	to   string
+}
+
+func newGraph(states map[string]automaton.State, initialState string, edges map[edgeKey][]string) Graph {
+	g := Graph{
+		States:       slices.SortedFunc(maps.Values(states), func(a, b automaton.State) int { return cmp.Compare(a.Name, b.Name) }),
+		InitialState: initialState,
+		Edges:        make([]Edge, 0, len(edges)),
+	}
+	for key, labels := range edges {
+		slices.Sort(labels)
+		g.Edges = append(g.Edges, Edge{From: key.from, To: key.to, Labels: labels})
+	}
+	slices.SortFunc(g.Edges, func(a, b Edge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+	return g
+}
@@ -0,0 +1,116 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewGraph(t *testing.T) {
+	data := []struct {
+		name           string
+		automaton      automaton.Automaton
+		expected       Graph
+		expectedErrMsg string
+	}{
+		{
+			"deterministic finite automaton",
+			&automaton.DeterministicFiniteAutomaton{
+				States: map[string]automaton.State{
+					"qB": {Name: "qB", Accepting: true},
+					"qA": {Name: "qA"},
+				},
+				CurrentState: "qA",
+				Transitions: automaton.DFATransitionFunction{
+					{StateName: "qA", SymbolName: "1"}: {StateName: "qB"},
+					{StateName: "qA", SymbolName: "0"}: {StateName: "qB"},
+					{StateName: "qB", SymbolName: "0"}: {StateName: "qB"},
+				},
+			},
+			Graph{
+				States: []automaton.State{
+					{Name: "qA"},
+					{Name: "qB", Accepting: true},
+				},
+				InitialState: "qA",
+				Edges: []Edge{
+					{From: "qA", To: "qB", Labels: []string{"0", "1"}},
+					{From: "qB", To: "qB", Labels: []string{"0"}},
+				},
+			},
+			"",
+		},
+		{
+			"pushdown automaton",
+			&automaton.PushdownAutomaton{
+				States: map[string]automaton.State{
+					"qA": {Name: "qA"},
+				},
+				CurrentState: "qA",
+				Transitions: automaton.PATransitionFunction{
+					{StateName: "qA", InputSymbolName: "0", StackSymbolName: "}"}:  {StateName: "qA", StackSymbolNames: []string{"}", "X"}},
+					{StateName: "qA", InputSymbolName: "1", StackSymbolName: "X"}:  {StateName: "qA", StackSymbolNames: []string{}},
+					{StateName: "qA", InputSymbolName: "2", StackSymbolName: "XY"}: {StateName: "qA", StackSymbolNames: []string{"X", "XY"}},
+				},
+			},
+			Graph{
+				States: []automaton.State{
+					{Name: "qA"},
+				},
+				InitialState: "qA",
+				Edges: []Edge{
+					{From: "qA", To: "qA", Labels: []string{"0, } / }X", "1, X / ε", "2, XY / X XY"}},
+				},
+			},
+			"",
+		},
+		{
+			"turing machine",
+			&automaton.TuringMachine{
+				States: map[string]automaton.State{
+					"qA": {Name: "qA"},
+					"qB": {Name: "qB", Accepting: true},
+				},
+				CurrentState: "qA",
+				Transitions: automaton.TMTransitionFunction{
+					{StateName: "qA", SymbolName: "1"}: {StateName: "qA", SymbolName: "1", Move: automaton.TapeMoveRight},
+					{StateName: "qA", SymbolName: "B"}: {StateName: "qB", SymbolName: "1", Move: automaton.TapeMoveLeft},
+				},
+			},
+			Graph{
+				States: []automaton.State{
+					{Name: "qA"},
+					{Name: "qB", Accepting: true},
+				},
+				InitialState: "qA",
+				Edges: []Edge{
+					{From: "qA", To: "qA", Labels: []string{"1 → 1, R"}},
+					{From: "qA", To: "qB", Labels: []string{"B → 1, L"}},
+				},
+			},
+			"",
+		},
+		{
+			"unsupported automaton",
+			nil,
+			Graph{},
+			"unsupported automaton type: <nil>",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			result, err := NewGraph(d.automaton)
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Error(diff)
+			}
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
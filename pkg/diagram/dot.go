@@ -0,0 +1,72 @@
+package diagram
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotEscaper escapes characters with special meaning inside DOT quoted strings
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotLeftJustifiedEscaper works like `dotEscaper`, but lines are left-justified, which keeps
+// alignment of monospace text (e.g. head position marker under the tape)
+var dotLeftJustifiedEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`)
+
+// WriteDOT writes graph in the Graphviz DOT language, accepting states are drawn as double circles
+// and parallel transitions are merged into a single edge with one label per line
+func (g Graph) WriteDOT(w io.Writer) error {
+	return g.writeDOT(w, nil)
+}
+
+// WriteDOTFrame works like `WriteDOT`, but it highlights the current state and the last taken edge
+// from `f` and renders its configuration below the diagram
+func (g Graph) WriteDOTFrame(w io.Writer, f Frame) error {
+	return g.writeDOT(w, &f)
+}
+
+func (g Graph) writeDOT(w io.Writer, f *Frame) error {
+	var sb strings.Builder
+	sb.WriteString("digraph automaton {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=circle];\n")
+	sb.WriteString("\t__start [shape=point];\n")
+	for _, s := range g.States {
+		attrs := make([]string, 0, 3)
+		if s.Accepting {
+			attrs = append(attrs, "shape=doublecircle")
+		}
+		if f != nil && f.State == s.Name {
+			attrs = append(attrs, "style=filled", "fillcolor=gold")
+		}
+		fmt.Fprintf(&sb, "\t%s%s;\n", dotID(s.Name), dotAttributes(attrs))
+	}
+	fmt.Fprintf(&sb, "\t__start -> %s;\n", dotID(g.InitialState))
+	for _, e := range g.Edges {
+		attrs := []string{"label=" + dotID(e.Label("\n"))}
+		if f != nil && f.PreviousState == e.From && f.State == e.To {
+			attrs = append(attrs, "color=red", "fontcolor=red", "penwidth=2")
+		}
+		fmt.Fprintf(&sb, "\t%s -> %s%s;\n", dotID(e.From), dotID(e.To), dotAttributes(attrs))
+	}
+	if f != nil {
+		label := `"` + dotLeftJustifiedEscaper.Replace(f.Configuration) + `"`
+		fmt.Fprintf(&sb, "\t__configuration [shape=box, fontname=monospace, label=%s];\n", label)
+	}
+	sb.WriteString("}\n")
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// dotID returns `s` as DOT quoted string
+func dotID(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// dotAttributes returns attribute list in DOT syntax, it returns empty string if there are no attributes
+func dotAttributes(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
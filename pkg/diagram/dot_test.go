@@ -0,0 +1,73 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g := Graph{
+		States: []automaton.State{
+			{Name: "qA"},
+			{Name: "qB", Accepting: true},
+		},
+		InitialState: "qA",
+		Edges: []Edge{
+			{From: "qA", To: "qB", Labels: []string{"0", "1"}},
+			{From: "qB", To: "qB", Labels: []string{`"`}},
+		},
+	}
+	var result strings.Builder
+	if err := g.WriteDOT(&result); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph automaton {
+	rankdir=LR;
+	node [shape=circle];
+	__start [shape=point];
+	"qA";
+	"qB" [shape=doublecircle];
+	__start -> "qA";
+	"qA" -> "qB" [label="0\n1"];
+	"qB" -> "qB" [label="\""];
+}
+`
+	if result.String() != expected {
+		t.Errorf("invalid result string, expected:\n%s, got:\n%s", expected, result.String())
+	}
+}
+
+func TestWriteDOTFrame(t *testing.T) {
+	g := Graph{
+		States: []automaton.State{
+			{Name: "qA"},
+			{Name: "qB", Accepting: true},
+		},
+		InitialState: "qA",
+		Edges: []Edge{
+			{From: "qA", To: "qB", Labels: []string{"0"}},
+			{From: "qB", To: "qB", Labels: []string{"1"}},
+		},
+	}
+	f := Frame{State: "qB", PreviousState: "qA", Configuration: "current state: qB, tape: 0|1\n                          ^\n"}
+	var result strings.Builder
+	if err := g.WriteDOTFrame(&result, f); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph automaton {
+	rankdir=LR;
+	node [shape=circle];
+	__start [shape=point];
+	"qA";
+	"qB" [shape=doublecircle, style=filled, fillcolor=gold];
+	__start -> "qA";
+	"qA" -> "qB" [label="0", color=red, fontcolor=red, penwidth=2];
+	"qB" -> "qB" [label="1"];
+	__configuration [shape=box, fontname=monospace, label="current state: qB, tape: 0|1\l                          ^\l"];
+}
+`
+	if result.String() != expected {
+		t.Errorf("invalid result string, expected:\n%s, got:\n%s", expected, result.String())
+	}
+}
@@ -0,0 +1,113 @@
+package diagram
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const htmlAnimationStyle = `body { font-family: sans-serif; margin: 20px; }
+#controls { margin-bottom: 10px; }
+#controls button { min-width: 40px; }
+.node circle { fill: white; stroke: #222; stroke-width: 1.5; }
+.node text { text-anchor: middle; dominant-baseline: central; font-size: 12px; }
+.node.active circle { fill: #ffd54f; }
+.edge path, .initial { fill: none; stroke: #444; stroke-width: 1.2; }
+.edge path { marker-end: url(#arrow); }
+.edge text { font-size: 12px; fill: #444; }
+.edge.active path { stroke: #d32f2f; stroke-width: 3; marker-end: url(#arrow-active); }
+.edge.active text { fill: #d32f2f; font-weight: bold; }
+#configuration { background: #f5f5f5; padding: 10px; }
+`
+
+const htmlAnimationScript = `let current = 0;
+let timer = null;
+const slider = document.getElementById("slider");
+slider.max = frames.length - 1;
+function show(i) {
+	current = Math.max(0, Math.min(frames.length - 1, i));
+	document.querySelectorAll(".active").forEach(e => e.classList.remove("active"));
+	const f = frames[current];
+	document.getElementById("s" + f.state).classList.add("active");
+	if (f.edge >= 0) {
+		document.getElementById("e" + f.edge).classList.add("active");
+	}
+	document.getElementById("configuration").textContent = f.configuration;
+	document.getElementById("step").textContent = "step " + current + " / " + (frames.length - 1);
+	slider.value = current;
+}
+function stop() {
+	clearInterval(timer);
+	timer = null;
+	document.getElementById("play").textContent = "Play";
+}
+document.getElementById("first").onclick = () => { stop(); show(0); };
+document.getElementById("prev").onclick = () => { stop(); show(current - 1); };
+document.getElementById("next").onclick = () => { stop(); show(current + 1); };
+document.getElementById("last").onclick = () => { stop(); show(frames.length - 1); };
+slider.oninput = () => { stop(); show(Number(slider.value)); };
+document.getElementById("play").onclick = () => {
+	if (timer !== null) {
+		stop();
+		return;
+	}
+	if (current === frames.length - 1) {
+		show(0);
+	}
+	document.getElementById("play").textContent = "Pause";
+	timer = setInterval(() => {
+		if (current === frames.length - 1) {
+			stop();
+			return;
+		}
+		show(current + 1);
+	}, 700);
+};
+show(0);
+`
+
+// htmlFrame is a frame as used by the animation script, states and edges are referenced by their index
+type htmlFrame struct {
+	State         int    `json:"state"`
+	Edge          int    `json:"edge"`
+	Configuration string `json:"configuration"`
+}
+
+// WriteHTMLAnimation writes self-contained HTML page with the diagram of the automaton and controls allowing
+// to go through `frames` step by step, current state and last taken edge are highlighted in every step
+func (g Graph) WriteHTMLAnimation(w io.Writer, frames []Frame) error {
+	stateIds := make(map[string]int, len(g.States))
+	for i, s := range g.States {
+		stateIds[s.Name] = i
+	}
+	edgeIds := make(map[edgeKey]int, len(g.Edges))
+	for i, e := range g.Edges {
+		edgeIds[edgeKey{from: e.From, to: e.To}] = i
+	}
+	hf := make([]htmlFrame, 0, len(frames))
+	for _, f := range frames {
+		edge, ok := edgeIds[edgeKey{from: f.PreviousState, to: f.State}]
+		if !ok {
+			edge = -1
+		}
+		hf = append(hf, htmlFrame{State: stateIds[f.State], Edge: edge, Configuration: f.Configuration})
+	}
+	// json.Marshal escapes '<', '>' and '&' so it's safe to embed inside script tag
+	framesJSON, err := json.Marshal(hf)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Automaton run</title>\n")
+	sb.WriteString("<style>\n" + htmlAnimationStyle + "</style>\n</head>\n<body>\n")
+	sb.WriteString(`<div id="controls">` +
+		`<button id="first">&#x23EE;</button> <button id="prev">&#x25C0;</button> <button id="play">Play</button> ` +
+		`<button id="next">&#x25B6;</button> <button id="last">&#x23ED;</button> ` +
+		`<input type="range" id="slider" min="0" value="0"> <span id="step"></span></div>` + "\n")
+	g.writeSVG(&sb)
+	sb.WriteString("<pre id=\"configuration\"></pre>\n")
+	sb.WriteString("<script>\nconst frames = " + string(framesJSON) + ";\n" + htmlAnimationScript + "</script>\n")
+	sb.WriteString("</body>\n</html>\n")
+	_, err = w.Write([]byte(sb.String()))
+	return err
+}
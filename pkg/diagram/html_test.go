@@ -0,0 +1,43 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLAnimation(t *testing.T) {
+	g := Graph{
+		States: []automaton.State{
+			{Name: "qA"},
+			{Name: "qB", Accepting: true},
+		},
+		InitialState: "qA",
+		Edges: []Edge{
+			{From: "qA", To: "qB", Labels: []string{"<"}},
+		},
+	}
+	frames := []Frame{
+		{State: "qA", Configuration: "current state: qA, input left: <\n"},
+		{State: "qB", PreviousState: "qA", Configuration: "current state: qB, input left: \n"},
+	}
+	var sb strings.Builder
+	if err := g.WriteHTMLAnimation(&sb, frames); err != nil {
+		t.Fatal(err)
+	}
+	result := sb.String()
+	expectedParts := []string{
+		`<g class="node" id="s0">`,
+		`<g class="node" id="s1">`,
+		`<g class="edge" id="e0">`,
+		`<tspan x="`,
+		`&lt;</tspan>`,
+		`const frames = [{"state":0,"edge":-1,"configuration":"current state: qA, input left: \u003c\n"},` +
+			`{"state":1,"edge":0,"configuration":"current state: qB, input left: \n"}];`,
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(result, part) {
+			t.Errorf("expected result to contain:\n%s\ngot:\n%s", part, result)
+		}
+	}
+}
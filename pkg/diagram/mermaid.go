@@ -0,0 +1,36 @@
+package diagram
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// mermaidEscaper replaces characters that end or break transition label in Mermaid with entity codes
+var mermaidEscaper = strings.NewReplacer("#", "#35;", ";", "#59;", "{", "#123;", "}", "#125;")
+
+// WriteMermaid writes graph as Mermaid `stateDiagram-v2`, accepting states have transition to the final
+// pseudo state `[*]` and parallel transitions are merged into a single edge with one label per line
+func (g Graph) WriteMermaid(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("stateDiagram-v2\n")
+	sb.WriteString("\tdirection LR\n")
+	for _, s := range g.States {
+		fmt.Fprintf(&sb, "\t%s\n", s.Name)
+	}
+	fmt.Fprintf(&sb, "\t[*] --> %s\n", g.InitialState)
+	for _, e := range g.Edges {
+		labels := make([]string, 0, len(e.Labels))
+		for _, l := range e.Labels {
+			labels = append(labels, mermaidEscaper.Replace(l))
+		}
+		fmt.Fprintf(&sb, "\t%s --> %s: %s\n", e.From, e.To, strings.Join(labels, "<br/>"))
+	}
+	for _, s := range g.States {
+		if s.Accepting {
+			fmt.Fprintf(&sb, "\t%s --> [*]\n", s.Name)
+		}
+	}
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
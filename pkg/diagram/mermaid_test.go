@@ -0,0 +1,35 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"strings"
+	"testing"
+)
+
+func TestWriteMermaid(t *testing.T) {
+	g := Graph{
+		States: []automaton.State{
+			{Name: "qA"},
+			{Name: "qB", Accepting: true},
+		},
+		InitialState: "qA",
+		Edges: []Edge{
+			{From: "qA", To: "qB", Labels: []string{"0, } / ε", "1, X / XX"}},
+		},
+	}
+	var result strings.Builder
+	if err := g.WriteMermaid(&result); err != nil {
+		t.Fatal(err)
+	}
+	expected := `stateDiagram-v2
+	direction LR
+	qA
+	qB
+	[*] --> qA
+	qA --> qB: 0, #125; / ε<br/>1, X / XX
+	qB --> [*]
+`
+	if result.String() != expected {
+		t.Errorf("invalid result string, expected:\n%s, got:\n%s", expected, result.String())
+	}
+}
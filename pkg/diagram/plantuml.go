@@ -0,0 +1,31 @@
+package diagram
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WritePlantUML writes graph as PlantUML state diagram, accepting states have transition to the final
+// pseudo state `[*]` and parallel transitions are merged into a single edge with one label per line
+func (g Graph) WritePlantUML(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	sb.WriteString("hide empty description\n")
+	sb.WriteString("left to right direction\n")
+	for _, s := range g.States {
+		fmt.Fprintf(&sb, "state %s\n", s.Name)
+	}
+	fmt.Fprintf(&sb, "[*] --> %s\n", g.InitialState)
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "%s --> %s : %s\n", e.From, e.To, e.Label(`\n`))
+	}
+	for _, s := range g.States {
+		if s.Accepting {
+			fmt.Fprintf(&sb, "%s --> [*]\n", s.Name)
+		}
+	}
+	sb.WriteString("@enduml\n")
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
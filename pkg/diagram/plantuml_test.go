@@ -0,0 +1,39 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"strings"
+	"testing"
+)
+
+func TestWritePlantUML(t *testing.T) {
+	g := Graph{
+		States: []automaton.State{
+			{Name: "qA"},
+			{Name: "qB", Accepting: true},
+		},
+		InitialState: "qA",
+		Edges: []Edge{
+			{From: "qA", To: "qA", Labels: []string{"1 → 1, R"}},
+			{From: "qA", To: "qB", Labels: []string{"B → 1, L", "X → X, L"}},
+		},
+	}
+	var result strings.Builder
+	if err := g.WritePlantUML(&result); err != nil {
+		t.Fatal(err)
+	}
+	expected := `@startuml
+hide empty description
+left to right direction
+state qA
+state qB
+[*] --> qA
+qA --> qA : 1 → 1, R
+qA --> qB : B → 1, L\nX → X, L
+qB --> [*]
+@enduml
+`
+	if result.String() != expected {
+		t.Errorf("invalid result string, expected:\n%s, got:\n%s", expected, result.String())
+	}
+}
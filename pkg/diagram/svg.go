@@ -0,0 +1,151 @@
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+const (
+	// svgNodeRadius is the radius of the circle representing state
+	svgNodeRadius = 28.0
+	// svgMargin is the space around the circle layout, reserved for self loops and labels
+	svgMargin = 140.0
+	// svgNodeSpacing is the approximate distance between neighbouring states on the layout circle
+	svgNodeSpacing = 170.0
+	// svgCurvature is the distance between the middle of the edge and its control point, it keeps
+	// edges in opposite directions apart
+	svgCurvature = 30.0
+	// svgLoopSize is the distance between the state border and the control points of the self loop
+	svgLoopSize = 55.0
+)
+
+type point struct {
+	x float64
+	y float64
+}
+
+func (p point) add(o point) point     { return point{p.x + o.x, p.y + o.y} }
+func (p point) sub(o point) point     { return point{p.x - o.x, p.y - o.y} }
+func (p point) scale(f float64) point { return point{p.x * f, p.y * f} }
+func (p point) length() float64       { return math.Hypot(p.x, p.y) }
+func (p point) normal() point         { return point{-p.y, p.x} }
+func (p point) rotate(angle float64) point {
+	sin, cos := math.Sincos(angle)
+	return point{p.x*cos - p.y*sin, p.x*sin + p.y*cos}
+}
+
+func (p point) unit() point {
+	l := p.length()
+	if l == 0 {
+		return point{0, -1}
+	}
+	return p.scale(1 / l)
+}
+
+// svgLayout places states evenly on a circle, in the order of `Graph.States`
+type svgLayout struct {
+	size      float64
+	center    point
+	positions map[string]point
+}
+
+func newSVGLayout(g Graph) svgLayout {
+	n := float64(len(g.States))
+	radius := 0.0
+	if n > 1 {
+		radius = math.Max(svgNodeSpacing/2, n*svgNodeSpacing/(2*math.Pi))
+	}
+	l := svgLayout{
+		size:      2 * (radius + svgMargin),
+		center:    point{radius + svgMargin, radius + svgMargin},
+		positions: make(map[string]point, len(g.States)),
+	}
+	for i, s := range g.States {
+		// Start at the top of the circle and go clockwise
+		angle := 2*math.Pi*float64(i)/n - math.Pi/2
+		l.positions[s.Name] = l.center.add(point{math.Cos(angle), math.Sin(angle)}.scale(radius))
+	}
+	return l
+}
+
+// writeSVG writes graph as SVG, state with index `i` has id `s<i>` and edge with index `i` has id `e<i>`
+// so they can be highlighted by adding `active` class
+func (g Graph) writeSVG(sb *strings.Builder) {
+	l := newSVGLayout(g)
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n", l.size, l.size, l.size, l.size)
+	sb.WriteString(`<defs>` +
+		`<marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#444"/></marker>` +
+		`<marker id="arrow-active" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#d32f2f"/></marker>` +
+		"</defs>\n")
+	for i, e := range g.Edges {
+		fmt.Fprintf(sb, `<g class="edge" id="e%d">`, i)
+		l.writeEdge(sb, e)
+		sb.WriteString("</g>\n")
+	}
+	initial := l.positions[g.InitialState]
+	fmt.Fprintf(sb, `<path class="initial" d="M %.1f %.1f L %.1f %.1f" marker-end="url(#arrow)"/>`+"\n",
+		initial.x-svgNodeRadius-40, initial.y, initial.x-svgNodeRadius, initial.y)
+	for i, s := range g.States {
+		p := l.positions[s.Name]
+		fmt.Fprintf(sb, `<g class="node" id="s%d">`, i)
+		fmt.Fprintf(sb, `<circle cx="%.1f" cy="%.1f" r="%.1f"/>`, p.x, p.y, svgNodeRadius)
+		if s.Accepting {
+			fmt.Fprintf(sb, `<circle cx="%.1f" cy="%.1f" r="%.1f"/>`, p.x, p.y, svgNodeRadius-5)
+		}
+		fmt.Fprintf(sb, `<text x="%.1f" y="%.1f">%s</text>`, p.x, p.y, html.EscapeString(s.Name))
+		sb.WriteString("</g>\n")
+	}
+	sb.WriteString("</svg>\n")
+}
+
+func (l svgLayout) writeEdge(sb *strings.Builder, e Edge) {
+	from := l.positions[e.From]
+	to := l.positions[e.To]
+	if e.From == e.To {
+		// Self loop is drawn outside of the layout circle
+		out := from.sub(l.center).unit()
+		start := from.add(out.rotate(-math.Pi / 6).scale(svgNodeRadius))
+		end := from.add(out.rotate(math.Pi / 6).scale(svgNodeRadius))
+		c1 := from.add(out.rotate(-math.Pi / 6).scale(svgNodeRadius + svgLoopSize))
+		c2 := from.add(out.rotate(math.Pi / 6).scale(svgNodeRadius + svgLoopSize))
+		fmt.Fprintf(sb, `<path d="M %.1f %.1f C %.1f %.1f %.1f %.1f %.1f %.1f"/>`, start.x, start.y, c1.x, c1.y, c2.x, c2.y, end.x, end.y)
+		labelAt := from.add(out.scale(svgNodeRadius + svgLoopSize + 5))
+		writeSVGLabel(sb, e.Labels, labelAt, out)
+		return
+	}
+	direction := to.sub(from).unit()
+	control := from.add(to).scale(0.5).add(direction.normal().scale(svgCurvature))
+	start := from.add(control.sub(from).unit().scale(svgNodeRadius))
+	end := to.add(control.sub(to).unit().scale(svgNodeRadius))
+	fmt.Fprintf(sb, `<path d="M %.1f %.1f Q %.1f %.1f %.1f %.1f"/>`, start.x, start.y, control.x, control.y, end.x, end.y)
+	// Middle point of the quadratic curve
+	labelAt := start.scale(0.25).add(control.scale(0.5)).add(end.scale(0.25))
+	writeSVGLabel(sb, e.Labels, labelAt, direction.normal())
+}
+
+// writeSVGLabel writes multiline label next to `at`, on the side pointed by `side`
+func writeSVGLabel(sb *strings.Builder, lines []string, at point, side point) {
+	const lineHeight = 14.0
+	height := lineHeight * float64(len(lines))
+	// Move the label away from the edge, so it doesn't cross it
+	at = at.add(side.unit().scale(6))
+	anchor := "middle"
+	if side.x > 0.3 {
+		anchor = "start"
+	} else if side.x < -0.3 {
+		anchor = "end"
+	}
+	top := at.y - height/2 + lineHeight/2
+	if side.y < -0.3 {
+		top = at.y - height + lineHeight/2
+	} else if side.y > 0.3 {
+		top = at.y + lineHeight/2
+	}
+	fmt.Fprintf(sb, `<text class="label" text-anchor="%s">`, anchor)
+	for i, line := range lines {
+		fmt.Fprintf(sb, `<tspan x="%.1f" y="%.1f">%s</tspan>`, at.x, top+float64(i)*lineHeight, html.EscapeString(line))
+	}
+	sb.WriteString("</text>")
+}
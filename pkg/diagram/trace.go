@@ -0,0 +1,50 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"fmt"
+	"strings"
+)
+
+// Frame is a single step of the computation, shown on top of the automaton diagram
+type Frame struct {
+	// State automaton is in
+	State string
+	// PreviousState is the state automaton was in before the last move, it's empty for the first frame
+	PreviousState string
+	// Configuration is the text representation of the whole configuration (input left, stack or tape)
+	Configuration string
+}
+
+// NewFrames creates frames from the calculations states collected during the run (see `automaton.AutomatonOptions.Trace`)
+func NewFrames(trace []automaton.AutomatonCurrentCalculationsState) ([]Frame, error) {
+	frames := make([]Frame, 0, len(trace))
+	previous := ""
+	for _, cs := range trace {
+		state, err := stateOf(cs)
+		if err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		if err := cs.SaveState(&sb); err != nil {
+			return nil, err
+		}
+		frames = append(frames, Frame{State: state, PreviousState: previous, Configuration: sb.String()})
+		previous = state
+	}
+	return frames, nil
+}
+
+// stateOf returns name of the state from calculations state
+func stateOf(cs automaton.AutomatonCurrentCalculationsState) (string, error) {
+	switch v := cs.(type) {
+	case automaton.DeterministicFiniteAutomatonCurrentCalculationsState:
+		return v.State.Name, nil
+	case automaton.PushdownAutomatonCurrentCalculationsState:
+		return v.CurrentState.Name, nil
+	case automaton.TuringMachineCurrentCalculationsState:
+		return v.State.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported calculations state type: %T", cs)
+	}
+}
@@ -0,0 +1,32 @@
+package diagram
+
+import (
+	"automata-compiler/pkg/automaton"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewFrames(t *testing.T) {
+	trace := []automaton.AutomatonCurrentCalculationsState{
+		automaton.DeterministicFiniteAutomatonCurrentCalculationsState{
+			State:     automaton.State{Name: "qA"},
+			InputLeft: []automaton.Symbol{{Name: "0"}, {Name: "1"}},
+		},
+		automaton.DeterministicFiniteAutomatonCurrentCalculationsState{
+			State:     automaton.State{Name: "qB", Accepting: true},
+			InputLeft: []automaton.Symbol{{Name: "1"}},
+		},
+	}
+	result, err := NewFrames(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Frame{
+		{State: "qA", Configuration: "current state: qA, input left: 0|1\n"},
+		{State: "qB", PreviousState: "qA", Configuration: "current state: qB, input left: 1\n"},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid frames (-expected +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,41 @@
+package lexer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Highlight returns the line of the `source` containing the beginning of the `span`, followed by a line marking
+// the span with `^~~~`, e.g.
+//
+//	(qA, 0 > (qB)
+//	       ^
+//
+// Only the part of the span in the first line is marked, empty span is marked with a single `^`.
+func Highlight(source string, span Span) string {
+	start := min(max(span.Start, 0), len(source))
+	lineStart := strings.LastIndexByte(source[:start], '\n') + 1
+	lineEnd := len(source)
+	if i := strings.IndexByte(source[start:], '\n'); i >= 0 {
+		lineEnd = start + i
+	}
+	line := strings.TrimSuffix(source[lineStart:lineEnd], "\r")
+	end := min(max(span.End, start), lineStart+len(line))
+
+	var b strings.Builder
+	b.WriteString(line)
+	b.WriteByte('\n')
+	// Tabs are kept so the marker is aligned with the line regardless of the tab width
+	for _, r := range source[lineStart:start] {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	if n := utf8.RuneCountInString(source[start:end]); n > 1 {
+		b.WriteString(strings.Repeat("~", n-1))
+	}
+	return b.String()
+}
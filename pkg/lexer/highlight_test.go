@@ -0,0 +1,67 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHighlight(t *testing.T) {
+	data := []struct {
+		name     string
+		source   string
+		span     Span
+		expected string
+	}{
+		{
+			"single rune",
+			"qA;\n(qA, 0 > (qB)\n",
+			Span{Start: 11, End: 12},
+			"(qA, 0 > (qB)\n       ^",
+		},
+		{
+			"many runes",
+			"qA;\n(qA, symbol1) > (qB)",
+			Span{Start: 9, End: 16},
+			"(qA, symbol1) > (qB)\n     ^~~~~~~",
+		},
+		{
+			"first line",
+			"qA qA;\nqB;",
+			Span{Start: 3, End: 5},
+			"qA qA;\n   ^~",
+		},
+		{
+			"empty span at the end",
+			"qA;\nqA",
+			Span{Start: 6, End: 6},
+			"qA\n  ^",
+		},
+		{
+			"tabs are kept",
+			"\tqA\t;",
+			Span{Start: 4, End: 5},
+			"\tqA\t;\n\t  \t^",
+		},
+		{
+			"multibyte runes",
+			"qżółw ab",
+			Span{Start: 9, End: 11},
+			"qżółw ab\n      ^~",
+		},
+		{
+			"span longer than line",
+			"(qA,\n0)",
+			Span{Start: 1, End: 7},
+			"(qA,\n ^~~",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			result := Highlight(d.source, d.span)
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
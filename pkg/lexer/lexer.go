@@ -1,44 +1,171 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Lexerer interface {
 	ScanTokens() ([]Token, error)
 }
 
+// TokenReader returns tokens one by one, the last token is EOF and it's returned again by every following call
+type TokenReader interface {
+	Next() (Token, error)
+}
+
+// readChunkSize is the number of bytes read at once by lexers created with `NewReaderLexer`
+const readChunkSize = 4096
+
 type Lexer struct {
-	// Source code of the program
+	// Source code of the program, for lexers created with `NewReaderLexer` it holds only the part of the source
+	// that is read but not yet scanned
 	source string
+	// reader is the source of lexers created with `NewReaderLexer`, nil otherwise
+	reader io.Reader
+	// readDone is set when `reader` returns an error, including io.EOF
+	readDone bool
+	// readErr is the error returned by `reader`, other than io.EOF
+	readErr error
+	// base is the offset of `source` in the whole source code, it's non-zero only when reading from `reader`
+	base int
+
 	// List of tokens scanned from the source code
 	tokens []Token
 	// Current line
 	line int
-	// Start (rune id) of currenlty analyzed token
+	// Start (rune id) of the current line
+	lineStart int
+	// Start (rune id) of currenlty analyzed token, used only to count columns
 	start int
-	// End (rune id) of currently analyzed token (exclusive)
+	// End (rune id) of currently analyzed token (exclusive), used only to count columns
 	current int
+	// Byte offsets of `start` and `current`, the source is read using them so every rune is decoded only once
+	startOffset   int
+	currentOffset int
+
+	// pending is the token scanned ahead to tell whether '{' starts a class of symbols, see `Next`
+	pending *scanResult
+	// inClass is set after '{' starting a class of symbols, so the next '}' ends the class
+	inClass bool
+}
+
+type scanResult struct {
+	token Token
+	err   error
+}
+
+// classMembers are types of tokens that can follow '{' starting a class of symbols
+var classMembers = []TokenType{SymbolToken, BlankSymbolToken, MoveLeftToken, MoveRightToken}
+
+// reservedLetters maps letters used by TM to their token types
+var reservedLetters = map[string]TokenType{
+	"L": MoveLeftToken,
+	"R": MoveRightToken,
+	"B": BlankSymbolToken,
+}
+
+// LexError is returned when the source contains a fragment that doesn't form any token
+type LexError struct {
+	Line   int
+	Column int
+	Span   Span
+	// Fragment is the part of the source that couldn't be scanned
+	Fragment string
+	// Message describes the error, if it's empty the fragment is reported as an unknown symbol
+	Message string
+}
+
+func (e LexError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("[Line %d, Column %d] %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("[Line %d, Column %d] unknown symbol %s", e.Line, e.Column, e.Fragment)
 }
 
 func (l *Lexer) ScanTokens() ([]Token, error) {
 	for {
-		l.skipWhitespaces()
-		l.skipComments()
-		if l.isAtEnd() {
-			break
-		}
-		l.start = l.current
-		t, err := l.scanToken()
+		t, err := l.Next()
 		if err != nil {
 			var zero []Token
 			return zero, err
 		}
 		l.tokens = append(l.tokens, t)
+		if t.Type == EOFToken {
+			return l.tokens, nil
+		}
+	}
+}
+
+// Next scans and returns the next token, unlike `ScanTokens` it doesn't keep scanned tokens.
+// Braces enclose classes of symbols (e.g. `{0, 1}`), but in PA they are also the input end and stack start symbols,
+// so '{' starts a class only if it's followed by a symbol, e.g. it doesn't in `(q, {, })`.
+func (l *Lexer) Next() (Token, error) {
+	t, err := l.nextScanned()
+	if err != nil {
+		return t, err
+	}
+	switch t.Type {
+	case InputEndToken:
+		next, err := l.scanNext()
+		l.pending = &scanResult{token: next, err: err}
+		if err == nil && slices.Contains(classMembers, next.Type) {
+			t.Type = ClassStartToken
+			l.inClass = true
+		}
+	case StackStartToken:
+		if l.inClass {
+			t.Type = ClassEndToken
+			l.inClass = false
+		}
+	case LeftParenToken, RightParenToken, SemicolonToken, ArrowToken, EOFToken:
+		// Class can't contain these tokens, so unclosed class doesn't affect the rest of the source
+		l.inClass = false
+	}
+	return t, nil
+}
+
+// nextScanned returns the token scanned ahead, if there is one, or scans the next token
+func (l *Lexer) nextScanned() (Token, error) {
+	if l.pending != nil {
+		p := l.pending
+		l.pending = nil
+		return p.token, p.err
+	}
+	return l.scanNext()
+}
+
+// scanNext skips whitespaces and comments and scans the next token
+func (l *Lexer) scanNext() (Token, error) {
+	l.skipWhitespaces()
+	l.skipComments()
+	l.start, l.startOffset = l.current, l.currentOffset
+	if l.isAtEnd() {
+		// The source can be incomplete if reading failed
+		if l.readErr != nil {
+			return Token{}, l.readErr
+		}
+		return l.newToken(EOFToken, ""), nil
+	}
+	return l.scanToken()
+}
+
+// Tokens returns iterator over the remaining tokens, it stops after EOF token or the first error
+func (l *Lexer) Tokens() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			t, err := l.Next()
+			if !yield(t, err) || err != nil || t.Type == EOFToken {
+				return
+			}
+		}
 	}
-	l.tokens = append(l.tokens, Token{Type: EOFToken, Value: "", Line: l.line})
-	return l.tokens, nil
 }
 
 func NewLexer(source string) *Lexer {
@@ -51,59 +178,166 @@ func NewLexer(source string) *Lexer {
 	}
 }
 
-func (l Lexer) isAtEnd() bool {
-	return l.current >= len(l.source)
+// NewReaderLexer creates lexer reading the source from `r` in chunks, only the currently scanned part of
+// the source is kept in memory, so tokens should be read with `Next` or `Tokens`
+func NewReaderLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		reader:  r,
+		tokens:  make([]Token, 0),
+		line:    1,
+		start:   0,
+		current: 0,
+	}
+}
+
+func (l *Lexer) isAtEnd() bool {
+	return l.currentOffset >= len(l.source) && !l.fill()
+}
+
+// fill reads the next chunk of the source from `reader`, it returns false if nothing more can be read.
+// Part of the source before the current token is dropped, as it's not needed anymore.
+func (l *Lexer) fill() bool {
+	for l.reader != nil && !l.readDone {
+		buf := make([]byte, readChunkSize)
+		n, err := l.reader.Read(buf)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				l.readErr = err
+			}
+			l.readDone = true
+		}
+		if n > 0 {
+			drop := min(l.startOffset, l.currentOffset)
+			l.source = l.source[drop:] + string(buf[:n])
+			l.base += drop
+			l.startOffset -= drop
+			l.currentOffset -= drop
+			return true
+		}
+	}
+	return false
 }
 
 func (l *Lexer) scanToken() (Token, error) {
 	r := l.advance()
-	c := string(r)
+	// Slicing the source doesn't allocate, unlike converting the rune
+	c := l.sourceFragment(l.startOffset, l.currentOffset)
 
 	switch c {
 	case "q":
 		state := l.readAlphanumeric()
-		return Token{Type: StateToken, Value: state, Line: l.line}, nil
+		return l.newToken(StateToken, state), nil
+	case "@":
+		if c := l.peek(); !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			return Token{}, l.newError("missing state name after @")
+		}
+		state := l.readAlphanumeric()
+		return l.newToken(StateToken, state[1:]), nil
+	case "'", "\"":
+		return l.readQuoted(r)
 	case "(":
-		return Token{Type: LeftParenToken, Value: c, Line: l.line}, nil
+		return l.newToken(LeftParenToken, c), nil
 	case ")":
-		return Token{Type: RightParenToken, Value: c, Line: l.line}, nil
+		return l.newToken(RightParenToken, c), nil
 	case ",":
-		return Token{Type: CommaToken, Value: c, Line: l.line}, nil
+		return l.newToken(CommaToken, c), nil
 	case ";":
-		return Token{Type: SemicolonToken, Value: c, Line: l.line}, nil
+		return l.newToken(SemicolonToken, c), nil
 	case ">":
-		return Token{Type: ArrowToken, Value: c, Line: l.line}, nil
-	case "L":
-		return Token{Type: MoveLeftToken, Value: c, Line: l.line}, nil
-	case "R":
-		return Token{Type: MoveRightToken, Value: c, Line: l.line}, nil
-	case "B":
-		return Token{Type: BlankSymbolToken, Value: c, Line: l.line}, nil
+		return l.newToken(ArrowToken, c), nil
+	case "*":
+		return l.newToken(WildcardToken, c), nil
 	case "}":
-		return Token{Type: StackStartToken, Value: c, Line: l.line}, nil
+		return l.newToken(StackStartToken, c), nil
 	case "{":
-		return Token{Type: InputEndToken, Value: c, Line: l.line}, nil
+		return l.newToken(InputEndToken, c), nil
 	default:
 		if unicode.IsLetter(r) || unicode.IsDigit(r) {
 			symbol := l.readAlphanumeric()
-			return Token{Type: SymbolToken, Value: symbol, Line: l.line}, nil
+			if l.peek() == ':' {
+				l.advance()
+				return l.newToken(SectionToken, symbol), nil
+			}
+			if tt, ok := keywords[symbol]; ok {
+				return l.newToken(tt, symbol), nil
+			}
+			// Reserved letters form tokens only on their own, e.g. `Left` or `B1` are symbols
+			if tt, ok := reservedLetters[symbol]; ok {
+				return l.newToken(tt, symbol), nil
+			}
+			return l.newToken(SymbolToken, symbol), nil
 		}
 		var zero Token
-		return zero, fmt.Errorf("[Line %d] unknown symbol %s", l.line, c)
+		return zero, LexError{Line: l.line, Column: l.column(), Span: l.span(), Fragment: c}
+	}
+}
+
+// readQuoted reads symbol enclosed in `quote`, the opening quote is already consumed.
+// Quoted symbols can contain any runes except the quote and new line, and they are never reserved.
+func (l *Lexer) readQuoted(quote rune) (Token, error) {
+	for {
+		c := l.peek()
+		if l.isAtEnd() || c == '\n' {
+			return Token{}, l.newError("unterminated quoted symbol")
+		}
+		l.advance()
+		if c == quote {
+			break
+		}
+	}
+	symbol := l.sourceFragment(l.startOffset+1, l.currentOffset-1)
+	if symbol == "" {
+		return Token{}, l.newError("empty quoted symbol")
+	}
+	return l.newToken(SymbolToken, symbol), nil
+}
+
+// newError creates LexError with `message` for the fragment between `start` and `current`
+func (l Lexer) newError(message string) LexError {
+	return LexError{
+		Line:     l.line,
+		Column:   l.column(),
+		Span:     l.span(),
+		Fragment: l.sourceFragment(l.startOffset, l.currentOffset),
+		Message:  message,
 	}
 }
 
+// newToken creates token starting at `start`
+func (l Lexer) newToken(tt TokenType, value string) Token {
+	return Token{Type: tt, Value: value, Line: l.line, Column: l.column(), Span: l.span()}
+}
+
+// span returns fragment of the source between `start` and `current`
+func (l Lexer) span() Span {
+	return Span{Start: l.base + l.startOffset, End: l.base + l.currentOffset}
+}
+
+// column returns column of the rune at `start`
+func (l Lexer) column() int {
+	return l.start - l.lineStart + 1
+}
+
 func (l *Lexer) advance() rune {
-	c := l.peek()
+	if l.isAtEnd() {
+		return 0
+	}
+	for !utf8.FullRuneInString(l.source[l.currentOffset:]) && l.fill() {
+	}
+	c, size := utf8.DecodeRuneInString(l.source[l.currentOffset:])
 	l.current++
+	l.currentOffset += size
 	return c
 }
 
-func (l Lexer) peek() rune {
+func (l *Lexer) peek() rune {
 	if l.isAtEnd() {
 		return 0
 	}
-	c := []rune(l.source)[l.current]
+	// Rune can be split between chunks read from `reader`
+	for !utf8.FullRuneInString(l.source[l.currentOffset:]) && l.fill() {
+	}
+	c, _ := utf8.DecodeRuneInString(l.source[l.currentOffset:])
 	return c
 }
 
@@ -116,15 +350,16 @@ func (l *Lexer) readAlphanumeric() string {
 		}
 		l.advance()
 	}
-	return l.sourceFragment(l.start, l.current)
+	return l.sourceFragment(l.startOffset, l.currentOffset)
 }
 
-// sourceFragment returns fragment of the source code, starting at rune with id `from` and ending at rune
-// with id `to` (exclusive)
+// sourceFragment returns fragment of the source code, starting at byte `from` and ending at byte `to` (exclusive)
 func (l Lexer) sourceFragment(from, to int) string {
-	runes := []rune(l.source)
-	fragment := runes[from:to]
-	return string(fragment)
+	if l.reader != nil {
+		// Fragment of the chunk would keep the whole chunk in memory
+		return strings.Clone(l.source[from:to])
+	}
+	return l.source[from:to]
 }
 
 // skipWhitespaces calls advance until the next rune is not unicode whitespace
@@ -136,10 +371,11 @@ func (l *Lexer) skipWhitespaces() {
 		if !unicode.IsSpace(c) {
 			break
 		}
+		l.advance()
 		if c == '\n' {
 			l.line++
+			l.lineStart = l.current
 		}
-		l.advance()
 	}
 }
 
@@ -161,6 +397,7 @@ func (l *Lexer) skipLine() {
 		c := l.advance()
 		if c == '\n' {
 			l.line++
+			l.lineStart = l.current
 			break
 		}
 		if l.isAtEnd() {
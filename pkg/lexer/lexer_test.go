@@ -1,8 +1,15 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestNewLexer(t *testing.T) {
@@ -44,7 +51,7 @@ func TestScanTokens(t *testing.T) {
 			"empty",
 			"",
 			[]Token{
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: EOFToken, Value: "", Line: 1, Column: 1, Span: Span{Start: 0, End: 0}},
 			},
 			"",
 		},
@@ -52,8 +59,8 @@ func TestScanTokens(t *testing.T) {
 			"state",
 			"qSingle",
 			[]Token{
-				{Type: StateToken, Value: "qSingle", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: StateToken, Value: "qSingle", Line: 1, Column: 1, Span: Span{Start: 0, End: 7}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 8, Span: Span{Start: 7, End: 7}},
 			},
 			"",
 		},
@@ -61,13 +68,13 @@ func TestScanTokens(t *testing.T) {
 			"few states",
 			"qSingle qNext qNext   qAfterCouple\n\n\nqNext\t\r\n    qLast\n\n",
 			[]Token{
-				{Type: StateToken, Value: "qSingle", Line: 1},
-				{Type: StateToken, Value: "qNext", Line: 1},
-				{Type: StateToken, Value: "qNext", Line: 1},
-				{Type: StateToken, Value: "qAfterCouple", Line: 1},
-				{Type: StateToken, Value: "qNext", Line: 4},
-				{Type: StateToken, Value: "qLast", Line: 5},
-				{Type: EOFToken, Value: "", Line: 7},
+				{Type: StateToken, Value: "qSingle", Line: 1, Column: 1, Span: Span{Start: 0, End: 7}},
+				{Type: StateToken, Value: "qNext", Line: 1, Column: 9, Span: Span{Start: 8, End: 13}},
+				{Type: StateToken, Value: "qNext", Line: 1, Column: 15, Span: Span{Start: 14, End: 19}},
+				{Type: StateToken, Value: "qAfterCouple", Line: 1, Column: 23, Span: Span{Start: 22, End: 34}},
+				{Type: StateToken, Value: "qNext", Line: 4, Column: 1, Span: Span{Start: 37, End: 42}},
+				{Type: StateToken, Value: "qLast", Line: 5, Column: 5, Span: Span{Start: 49, End: 54}},
+				{Type: EOFToken, Value: "", Line: 7, Column: 1, Span: Span{Start: 56, End: 56}},
 			},
 			"",
 		},
@@ -75,8 +82,8 @@ func TestScanTokens(t *testing.T) {
 			"blank symbol",
 			"B",
 			[]Token{
-				{Type: BlankSymbolToken, Value: "B", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: BlankSymbolToken, Value: "B", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 2, Span: Span{Start: 1, End: 1}},
 			},
 			"",
 		},
@@ -84,9 +91,36 @@ func TestScanTokens(t *testing.T) {
 			"move tokens",
 			"L R",
 			[]Token{
-				{Type: MoveLeftToken, Value: "L", Line: 1},
-				{Type: MoveRightToken, Value: "R", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: MoveLeftToken, Value: "L", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: MoveRightToken, Value: "R", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 4, Span: Span{Start: 3, End: 3}},
+			},
+			"",
+		},
+		{
+			"symbols starting with reserved letters",
+			"Left Right B1 L1",
+			[]Token{
+				{Type: SymbolToken, Value: "Left", Line: 1, Column: 1, Span: Span{Start: 0, End: 4}},
+				{Type: SymbolToken, Value: "Right", Line: 1, Column: 6, Span: Span{Start: 5, End: 10}},
+				{Type: SymbolToken, Value: "B1", Line: 1, Column: 12, Span: Span{Start: 11, End: 13}},
+				{Type: SymbolToken, Value: "L1", Line: 1, Column: 15, Span: Span{Start: 14, End: 16}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 17, Span: Span{Start: 16, End: 16}},
+			},
+			"",
+		},
+		{
+			"reserved letters followed by other tokens",
+			"(L,R;B)",
+			[]Token{
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: MoveLeftToken, Value: "L", Line: 1, Column: 2, Span: Span{Start: 1, End: 2}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: MoveRightToken, Value: "R", Line: 1, Column: 4, Span: Span{Start: 3, End: 4}},
+				{Type: SemicolonToken, Value: ";", Line: 1, Column: 5, Span: Span{Start: 4, End: 5}},
+				{Type: BlankSymbolToken, Value: "B", Line: 1, Column: 6, Span: Span{Start: 5, End: 6}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 7, Span: Span{Start: 6, End: 7}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 8, Span: Span{Start: 7, End: 7}},
 			},
 			"",
 		},
@@ -94,9 +128,9 @@ func TestScanTokens(t *testing.T) {
 			"parens",
 			"( )",
 			[]Token{
-				{Type: LeftParenToken, Value: "(", Line: 1},
-				{Type: RightParenToken, Value: ")", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 4, Span: Span{Start: 3, End: 3}},
 			},
 			"",
 		},
@@ -104,10 +138,10 @@ func TestScanTokens(t *testing.T) {
 			"comma, semicolon & arrow",
 			", ; >",
 			[]Token{
-				{Type: CommaToken, Value: ",", Line: 1},
-				{Type: SemicolonToken, Value: ";", Line: 1},
-				{Type: ArrowToken, Value: ">", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: SemicolonToken, Value: ";", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: ArrowToken, Value: ">", Line: 1, Column: 5, Span: Span{Start: 4, End: 5}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 6, Span: Span{Start: 5, End: 5}},
 			},
 			"",
 		},
@@ -115,8 +149,8 @@ func TestScanTokens(t *testing.T) {
 			"symbol",
 			"someSymbol",
 			[]Token{
-				{Type: SymbolToken, Value: "someSymbol", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: SymbolToken, Value: "someSymbol", Line: 1, Column: 1, Span: Span{Start: 0, End: 10}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 11, Span: Span{Start: 10, End: 10}},
 			},
 			"",
 		},
@@ -124,9 +158,142 @@ func TestScanTokens(t *testing.T) {
 			"input end and stack start (pa's tokens)",
 			"{}",
 			[]Token{
-				{Type: InputEndToken, Value: "{", Line: 1},
-				{Type: StackStartToken, Value: "}", Line: 1},
-				{Type: EOFToken, Value: "", Line: 1},
+				{Type: InputEndToken, Value: "{", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: StackStartToken, Value: "}", Line: 1, Column: 2, Span: Span{Start: 1, End: 2}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 3, Span: Span{Start: 2, End: 2}},
+			},
+			"",
+		},
+		{
+			"tests section keywords",
+			"tests accept reject accepted",
+			[]Token{
+				{Type: TestsToken, Value: "tests", Line: 1, Column: 1, Span: Span{Start: 0, End: 5}},
+				{Type: AcceptToken, Value: "accept", Line: 1, Column: 7, Span: Span{Start: 6, End: 12}},
+				{Type: RejectToken, Value: "reject", Line: 1, Column: 14, Span: Span{Start: 13, End: 19}},
+				{Type: SymbolToken, Value: "accepted", Line: 1, Column: 21, Span: Span{Start: 20, End: 28}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 29, Span: Span{Start: 28, End: 28}},
+			},
+			"",
+		},
+		{
+			"type header and macro",
+			"type TM;macro",
+			[]Token{
+				{Type: TypeToken, Value: "type", Line: 1, Column: 1, Span: Span{Start: 0, End: 4}},
+				{Type: SymbolToken, Value: "TM", Line: 1, Column: 6, Span: Span{Start: 5, End: 7}},
+				{Type: SemicolonToken, Value: ";", Line: 1, Column: 8, Span: Span{Start: 7, End: 8}},
+				{Type: MacroToken, Value: "macro", Line: 1, Column: 9, Span: Span{Start: 8, End: 13}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 14, Span: Span{Start: 13, End: 13}},
+			},
+			"",
+		},
+		{
+			"section names",
+			"states: qA;\nsymbols:states;",
+			[]Token{
+				{Type: SectionToken, Value: "states", Line: 1, Column: 1, Span: Span{Start: 0, End: 7}},
+				{Type: StateToken, Value: "qA", Line: 1, Column: 9, Span: Span{Start: 8, End: 10}},
+				{Type: SemicolonToken, Value: ";", Line: 1, Column: 11, Span: Span{Start: 10, End: 11}},
+				{Type: SectionToken, Value: "symbols", Line: 2, Column: 1, Span: Span{Start: 12, End: 20}},
+				{Type: SymbolToken, Value: "states", Line: 2, Column: 9, Span: Span{Start: 20, End: 26}},
+				{Type: SemicolonToken, Value: ";", Line: 2, Column: 15, Span: Span{Start: 26, End: 27}},
+				{Type: EOFToken, Value: "", Line: 2, Column: 16, Span: Span{Start: 27, End: 27}},
+			},
+			"",
+		},
+		{
+			"space before colon of section name",
+			"symbols : a;",
+			zeroTokens,
+			"[Line 1, Column 9] unknown symbol :",
+		},
+		{
+			"wildcard and symbol class",
+			"(q1, *) > (q1, *, R) {0,1}",
+			[]Token{
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: StateToken, Value: "q1", Line: 1, Column: 2, Span: Span{Start: 1, End: 3}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 4, Span: Span{Start: 3, End: 4}},
+				{Type: WildcardToken, Value: "*", Line: 1, Column: 6, Span: Span{Start: 5, End: 6}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 7, Span: Span{Start: 6, End: 7}},
+				{Type: ArrowToken, Value: ">", Line: 1, Column: 9, Span: Span{Start: 8, End: 9}},
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 11, Span: Span{Start: 10, End: 11}},
+				{Type: StateToken, Value: "q1", Line: 1, Column: 12, Span: Span{Start: 11, End: 13}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 14, Span: Span{Start: 13, End: 14}},
+				{Type: WildcardToken, Value: "*", Line: 1, Column: 16, Span: Span{Start: 15, End: 16}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 17, Span: Span{Start: 16, End: 17}},
+				{Type: MoveRightToken, Value: "R", Line: 1, Column: 19, Span: Span{Start: 18, End: 19}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 20, Span: Span{Start: 19, End: 20}},
+				{Type: ClassStartToken, Value: "{", Line: 1, Column: 22, Span: Span{Start: 21, End: 22}},
+				{Type: SymbolToken, Value: "0", Line: 1, Column: 23, Span: Span{Start: 22, End: 23}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 24, Span: Span{Start: 23, End: 24}},
+				{Type: SymbolToken, Value: "1", Line: 1, Column: 25, Span: Span{Start: 24, End: 25}},
+				{Type: ClassEndToken, Value: "}", Line: 1, Column: 26, Span: Span{Start: 25, End: 26}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 27, Span: Span{Start: 26, End: 26}},
+			},
+			"",
+		},
+		{
+			"input end and stack start symbols",
+			"(q, {, })",
+			[]Token{
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: StateToken, Value: "q", Line: 1, Column: 2, Span: Span{Start: 1, End: 2}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: InputEndToken, Value: "{", Line: 1, Column: 5, Span: Span{Start: 4, End: 5}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 6, Span: Span{Start: 5, End: 6}},
+				{Type: StackStartToken, Value: "}", Line: 1, Column: 8, Span: Span{Start: 7, End: 8}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 9, Span: Span{Start: 8, End: 9}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 10, Span: Span{Start: 9, End: 9}},
+			},
+			"",
+		},
+		{
+			"symbol class followed by stack start symbol",
+			"(q, {a, # comment\nB}, })",
+			[]Token{
+				{Type: LeftParenToken, Value: "(", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: StateToken, Value: "q", Line: 1, Column: 2, Span: Span{Start: 1, End: 2}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: ClassStartToken, Value: "{", Line: 1, Column: 5, Span: Span{Start: 4, End: 5}},
+				{Type: SymbolToken, Value: "a", Line: 1, Column: 6, Span: Span{Start: 5, End: 6}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 7, Span: Span{Start: 6, End: 7}},
+				{Type: BlankSymbolToken, Value: "B", Line: 2, Column: 1, Span: Span{Start: 18, End: 19}},
+				{Type: ClassEndToken, Value: "}", Line: 2, Column: 2, Span: Span{Start: 19, End: 20}},
+				{Type: CommaToken, Value: ",", Line: 2, Column: 3, Span: Span{Start: 20, End: 21}},
+				{Type: StackStartToken, Value: "}", Line: 2, Column: 5, Span: Span{Start: 22, End: 23}},
+				{Type: RightParenToken, Value: ")", Line: 2, Column: 6, Span: Span{Start: 23, End: 24}},
+				{Type: EOFToken, Value: "", Line: 2, Column: 7, Span: Span{Start: 24, End: 24}},
+			},
+			"",
+		},
+		{
+			"unclosed symbol class",
+			"{a) }",
+			[]Token{
+				{Type: ClassStartToken, Value: "{", Line: 1, Column: 1, Span: Span{Start: 0, End: 1}},
+				{Type: SymbolToken, Value: "a", Line: 1, Column: 2, Span: Span{Start: 1, End: 2}},
+				{Type: RightParenToken, Value: ")", Line: 1, Column: 3, Span: Span{Start: 2, End: 3}},
+				{Type: StackStartToken, Value: "}", Line: 1, Column: 5, Span: Span{Start: 4, End: 5}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 6, Span: Span{Start: 5, End: 5}},
+			},
+			"",
+		},
+		{
+			"error after input end symbol",
+			"{ |",
+			zeroTokens,
+			"[Line 1, Column 3] unknown symbol |",
+		},
+		{
+			"multibyte runes",
+			"qżółw ab\nźdźbło",
+			[]Token{
+				{Type: StateToken, Value: "qżółw", Line: 1, Column: 1, Span: Span{Start: 0, End: 8}},
+				{Type: SymbolToken, Value: "ab", Line: 1, Column: 7, Span: Span{Start: 9, End: 11}},
+				{Type: SymbolToken, Value: "źdźbło", Line: 2, Column: 1, Span: Span{Start: 12, End: 21}},
+				{Type: EOFToken, Value: "", Line: 2, Column: 7, Span: Span{Start: 21, End: 21}},
 			},
 			"",
 		},
@@ -134,33 +301,30 @@ func TestScanTokens(t *testing.T) {
 			"invalid token",
 			"|321321",
 			zeroTokens,
-			"[Line 1] unknown symbol |",
+			"[Line 1, Column 1] unknown symbol |",
 		},
 		{
 			"all",
 			"qState\n;;,>>symbol1 symbol2\tBLRR,,((\n{{})",
 			[]Token{
-				{Type: StateToken, Value: "qState", Line: 1},
-				{Type: SemicolonToken, Value: ";", Line: 2},
-				{Type: SemicolonToken, Value: ";", Line: 2},
-				{Type: CommaToken, Value: ",", Line: 2},
-				{Type: ArrowToken, Value: ">", Line: 2},
-				{Type: ArrowToken, Value: ">", Line: 2},
-				{Type: SymbolToken, Value: "symbol1", Line: 2},
-				{Type: SymbolToken, Value: "symbol2", Line: 2},
-				{Type: BlankSymbolToken, Value: "B", Line: 2},
-				{Type: MoveLeftToken, Value: "L", Line: 2},
-				{Type: MoveRightToken, Value: "R", Line: 2},
-				{Type: MoveRightToken, Value: "R", Line: 2},
-				{Type: CommaToken, Value: ",", Line: 2},
-				{Type: CommaToken, Value: ",", Line: 2},
-				{Type: LeftParenToken, Value: "(", Line: 2},
-				{Type: LeftParenToken, Value: "(", Line: 2},
-				{Type: InputEndToken, Value: "{", Line: 3},
-				{Type: InputEndToken, Value: "{", Line: 3},
-				{Type: StackStartToken, Value: "}", Line: 3},
-				{Type: RightParenToken, Value: ")", Line: 3},
-				{Type: EOFToken, Value: "", Line: 3},
+				{Type: StateToken, Value: "qState", Line: 1, Column: 1, Span: Span{Start: 0, End: 6}},
+				{Type: SemicolonToken, Value: ";", Line: 2, Column: 1, Span: Span{Start: 7, End: 8}},
+				{Type: SemicolonToken, Value: ";", Line: 2, Column: 2, Span: Span{Start: 8, End: 9}},
+				{Type: CommaToken, Value: ",", Line: 2, Column: 3, Span: Span{Start: 9, End: 10}},
+				{Type: ArrowToken, Value: ">", Line: 2, Column: 4, Span: Span{Start: 10, End: 11}},
+				{Type: ArrowToken, Value: ">", Line: 2, Column: 5, Span: Span{Start: 11, End: 12}},
+				{Type: SymbolToken, Value: "symbol1", Line: 2, Column: 6, Span: Span{Start: 12, End: 19}},
+				{Type: SymbolToken, Value: "symbol2", Line: 2, Column: 14, Span: Span{Start: 20, End: 27}},
+				{Type: SymbolToken, Value: "BLRR", Line: 2, Column: 22, Span: Span{Start: 28, End: 32}},
+				{Type: CommaToken, Value: ",", Line: 2, Column: 26, Span: Span{Start: 32, End: 33}},
+				{Type: CommaToken, Value: ",", Line: 2, Column: 27, Span: Span{Start: 33, End: 34}},
+				{Type: LeftParenToken, Value: "(", Line: 2, Column: 28, Span: Span{Start: 34, End: 35}},
+				{Type: LeftParenToken, Value: "(", Line: 2, Column: 29, Span: Span{Start: 35, End: 36}},
+				{Type: InputEndToken, Value: "{", Line: 3, Column: 1, Span: Span{Start: 37, End: 38}},
+				{Type: InputEndToken, Value: "{", Line: 3, Column: 2, Span: Span{Start: 38, End: 39}},
+				{Type: StackStartToken, Value: "}", Line: 3, Column: 3, Span: Span{Start: 39, End: 40}},
+				{Type: RightParenToken, Value: ")", Line: 3, Column: 4, Span: Span{Start: 40, End: 41}},
+				{Type: EOFToken, Value: "", Line: 3, Column: 5, Span: Span{Start: 41, End: 41}},
 			},
 			"",
 		},
@@ -168,9 +332,9 @@ func TestScanTokens(t *testing.T) {
 			"with comments",
 			"qState\n#this line should be skipped\n\n#comment number 1\n### comment number 2\nqState",
 			[]Token{
-				{Type: StateToken, Value: "qState", Line: 1},
-				{Type: StateToken, Value: "qState", Line: 6},
-				{Type: EOFToken, Value: "", Line: 6},
+				{Type: StateToken, Value: "qState", Line: 1, Column: 1, Span: Span{Start: 0, End: 6}},
+				{Type: StateToken, Value: "qState", Line: 6, Column: 1, Span: Span{Start: 76, End: 82}},
+				{Type: EOFToken, Value: "", Line: 6, Column: 7, Span: Span{Start: 82, End: 82}},
 			},
 			"",
 		},
@@ -178,11 +342,60 @@ func TestScanTokens(t *testing.T) {
 			"with comments at the end with no new line after last comment",
 			"qState\n#this line should be skipped\n#this should also be skipped",
 			[]Token{
-				{Type: StateToken, Value: "qState", Line: 1},
-				{Type: EOFToken, Value: "", Line: 3},
+				{Type: StateToken, Value: "qState", Line: 1, Column: 1, Span: Span{Start: 0, End: 6}},
+				{Type: EOFToken, Value: "", Line: 3, Column: 29, Span: Span{Start: 64, End: 64}},
+			},
+			"",
+		},
+		{
+			"quoted symbols",
+			"'q' \"L\" '#' 'tests' 'a\"b'",
+			[]Token{
+				{Type: SymbolToken, Value: "q", Line: 1, Column: 1, Span: Span{Start: 0, End: 3}},
+				{Type: SymbolToken, Value: "L", Line: 1, Column: 5, Span: Span{Start: 4, End: 7}},
+				{Type: SymbolToken, Value: "#", Line: 1, Column: 9, Span: Span{Start: 8, End: 11}},
+				{Type: SymbolToken, Value: "tests", Line: 1, Column: 13, Span: Span{Start: 12, End: 19}},
+				{Type: SymbolToken, Value: "a\"b", Line: 1, Column: 21, Span: Span{Start: 20, End: 25}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 26, Span: Span{Start: 25, End: 25}},
 			},
 			"",
 		},
+		{
+			"states with sigil",
+			"@start @1,@q",
+			[]Token{
+				{Type: StateToken, Value: "start", Line: 1, Column: 1, Span: Span{Start: 0, End: 6}},
+				{Type: StateToken, Value: "1", Line: 1, Column: 8, Span: Span{Start: 7, End: 9}},
+				{Type: CommaToken, Value: ",", Line: 1, Column: 10, Span: Span{Start: 9, End: 10}},
+				{Type: StateToken, Value: "q", Line: 1, Column: 11, Span: Span{Start: 10, End: 12}},
+				{Type: EOFToken, Value: "", Line: 1, Column: 13, Span: Span{Start: 12, End: 12}},
+			},
+			"",
+		},
+		{
+			"unterminated quoted symbol",
+			"qA 'ab\n'",
+			zeroTokens,
+			"[Line 1, Column 4] unterminated quoted symbol",
+		},
+		{
+			"quoted symbol at the end",
+			"qA \"ab",
+			zeroTokens,
+			"[Line 1, Column 4] unterminated quoted symbol",
+		},
+		{
+			"empty quoted symbol",
+			"qA ''",
+			zeroTokens,
+			"[Line 1, Column 4] empty quoted symbol",
+		},
+		{
+			"missing state name after sigil",
+			"qA @ qB",
+			zeroTokens,
+			"[Line 1, Column 4] missing state name after @",
+		},
 	}
 	for _, d := range data {
 		t.Run(d.name, func(t *testing.T) {
@@ -201,3 +414,161 @@ func TestScanTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestLexError(t *testing.T) {
+	_, err := NewLexer("qA;\n  (qA, |)").ScanTokens()
+	var lexErr LexError
+	if !errors.As(err, &lexErr) {
+		t.Fatalf("expected LexError, got: %v", err)
+	}
+	expected := LexError{Line: 2, Column: 8, Span: Span{Start: 11, End: 12}, Fragment: "|"}
+	if diff := cmp.Diff(expected, lexErr); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestReaderLexer(t *testing.T) {
+	data := []struct {
+		name   string
+		source string
+	}{
+		{"empty", ""},
+		{"all", "qState\n;;,>>symbol1 symbol2\tBLRR,,((\n{{})"},
+		{"with comments", "qState\n#this line should be skipped\n\n#comment number 1\n### comment number 2\nqState"},
+		{"multibyte runes", "qżółw ab\nźdźbło"},
+		{"longer than chunk", generateSource(200)},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			expected, err := NewLexer(d.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Reading one byte at a time splits multibyte runes and tokens between chunks
+			readers := map[string]io.Reader{
+				"chunks":     strings.NewReader(d.source),
+				"one byte":   iotest.OneByteReader(strings.NewReader(d.source)),
+				"data + EOF": iotest.DataErrReader(strings.NewReader(d.source)),
+			}
+			for name, r := range readers {
+				result := make([]Token, 0)
+				for token, err := range NewReaderLexer(r).Tokens() {
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+					result = append(result, token)
+				}
+				if diff := cmp.Diff(expected, result); diff != "" {
+					t.Errorf("%s: %s", name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderLexerErrors(t *testing.T) {
+	readErr := errors.New("read failed")
+	data := []struct {
+		name           string
+		reader         io.Reader
+		expected       []Token
+		expectedErrMsg string
+	}{
+		{
+			"read error",
+			io.MultiReader(strings.NewReader("qA ;"), iotest.ErrReader(readErr)),
+			[]Token{
+				{Type: StateToken, Value: "qA", Line: 1, Column: 1, Span: Span{Start: 0, End: 2}},
+				{Type: SemicolonToken, Value: ";", Line: 1, Column: 4, Span: Span{Start: 3, End: 4}},
+			},
+			"read failed",
+		},
+		{
+			"unknown symbol",
+			strings.NewReader("qA\n|"),
+			[]Token{
+				{Type: StateToken, Value: "qA", Line: 1, Column: 1, Span: Span{Start: 0, End: 2}},
+			},
+			"[Line 2, Column 1] unknown symbol |",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			l := NewReaderLexer(d.reader)
+			result := make([]Token, 0)
+			var errMsg string
+			for {
+				token, err := l.Next()
+				if err != nil {
+					errMsg = err.Error()
+					break
+				}
+				result = append(result, token)
+			}
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Error(diff)
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestNextAfterEOF(t *testing.T) {
+	l := NewLexer("qA")
+	for range 2 {
+		if _, err := l.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// EOF is returned again
+	token, err := l.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Token{Type: EOFToken, Value: "", Line: 1, Column: 3, Span: Span{Start: 2, End: 2}}
+	if diff := cmp.Diff(expected, token); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// generateSource returns source of TM with `n` states and `n` transitions, similar to generated machines
+func generateSource(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		fmt.Fprintf(&sb, "qS%d ", i)
+	}
+	sb.WriteString(";\nqS0;\nqS0;\nzero one;\n# transitions\n")
+	for i := range n {
+		fmt.Fprintf(&sb, "(qS%d, zero) > (qS%d, one, R)\n", i, (i+1)%n)
+	}
+	sb.WriteString(";\nzero one zero;\n")
+	return sb.String()
+}
+
+func BenchmarkScanTokens(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		source := generateSource(n)
+		b.Run(fmt.Sprintf("%d transitions", n), func(b *testing.B) {
+			b.SetBytes(int64(len(source)))
+			for b.Loop() {
+				if _, err := NewLexer(source).ScanTokens(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReaderLexer(b *testing.B) {
+	source := generateSource(10000)
+	b.SetBytes(int64(len(source)))
+	for b.Loop() {
+		for _, err := range NewReaderLexer(strings.NewReader(source)).Tokens() {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
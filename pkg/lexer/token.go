@@ -1,5 +1,10 @@
 package lexer
 
+import (
+	"fmt"
+	"strings"
+)
+
 type TokenType int
 
 const (
@@ -22,8 +27,38 @@ const (
 	// Used in PA
 	InputEndToken
 	StackStartToken
+
+	// Used in tests section
+	TestsToken
+	AcceptToken
+	RejectToken
+
+	// Used in header
+	TypeToken
+
+	// Name of the section followed by ':', e.g. `states:`
+	SectionToken
+
+	// Used in transitions, `*` matches any symbol
+	WildcardToken
+
+	// Starts macro definition
+	MacroToken
+
+	// Used in transitions, braces enclosing class of symbols, e.g. `{0, 1}`
+	ClassStartToken
+	ClassEndToken
 )
 
+// keywords maps reserved words to their token types
+var keywords = map[string]TokenType{
+	"tests":  TestsToken,
+	"accept": AcceptToken,
+	"reject": RejectToken,
+	"type":   TypeToken,
+	"macro":  MacroToken,
+}
+
 func (tt TokenType) String() string {
 	switch tt {
 	case CommaToken:
@@ -52,13 +87,113 @@ func (tt TokenType) String() string {
 		return "InputEndToken"
 	case StackStartToken:
 		return "StackStartToken"
+	case TestsToken:
+		return "TestsToken"
+	case AcceptToken:
+		return "AcceptToken"
+	case RejectToken:
+		return "RejectToken"
+	case TypeToken:
+		return "TypeToken"
+	case SectionToken:
+		return "SectionToken"
+	case WildcardToken:
+		return "WildcardToken"
+	case MacroToken:
+		return "MacroToken"
+	case ClassStartToken:
+		return "ClassStartToken"
+	case ClassEndToken:
+		return "ClassEndToken"
 	default:
 		return "Invalid Token Type"
 	}
 }
 
+// Description returns the name of the token type used in error messages, e.g. "state name" or "';'"
+func (tt TokenType) Description() string {
+	switch tt {
+	case CommaToken:
+		return "','"
+	case ArrowToken:
+		return "'>'"
+	case LeftParenToken:
+		return "'('"
+	case RightParenToken:
+		return "')'"
+	case SemicolonToken:
+		return "';'"
+	case StateToken:
+		return "state name"
+	case SymbolToken:
+		return "symbol"
+	case BlankSymbolToken:
+		return "blank symbol 'B'"
+	case MoveLeftToken:
+		return "move 'L'"
+	case MoveRightToken:
+		return "move 'R'"
+	case EOFToken:
+		return "end of source"
+	case InputEndToken:
+		return "input end symbol '{'"
+	case StackStartToken:
+		return "stack start symbol '}'"
+	case TestsToken:
+		return "'tests'"
+	case AcceptToken:
+		return "'accept'"
+	case RejectToken:
+		return "'reject'"
+	case TypeToken:
+		return "'type'"
+	case SectionToken:
+		return "section name"
+	case WildcardToken:
+		return "wildcard '*'"
+	case MacroToken:
+		return "'macro'"
+	case ClassStartToken:
+		return "'{' starting symbol class"
+	case ClassEndToken:
+		return "'}' ending symbol class"
+	default:
+		return "invalid token"
+	}
+}
+
+// UnexpectedTokenErr returns an error saying that one of the `expected` token types was expected instead of `t`,
+// position of `t` is not included
+func UnexpectedTokenErr(t Token, expected ...TokenType) error {
+	return fmt.Errorf("expected %s, got %s", DescribeTokenTypes(expected...), t.Type.Description())
+}
+
+// DescribeTokenTypes returns human readable list of token types, e.g. "state name, symbol or ';'"
+func DescribeTokenTypes(types ...TokenType) string {
+	descriptions := make([]string, 0, len(types))
+	for _, tt := range types {
+		descriptions = append(descriptions, tt.Description())
+	}
+	if len(descriptions) == 1 {
+		return descriptions[0]
+	}
+	return strings.Join(descriptions[:len(descriptions)-1], ", ") + " or " + descriptions[len(descriptions)-1]
+}
+
+// Span is a fragment of the source code given in bytes
+type Span struct {
+	// Start is the offset of the first byte
+	Start int
+	// End is the offset right after the last byte, it's equal to `Start` for empty fragments (e.g. EOF)
+	End int
+}
+
 type Token struct {
 	Type  TokenType
 	Value string
 	Line  int
+	// Column of the first rune of the token, counted in runes starting from 1, 0 if unknown
+	Column int
+	// Span is the fragment of the source occupied by the token
+	Span Span
 }
@@ -0,0 +1,29 @@
+package lexer
+
+import "testing"
+
+func TestUnexpectedTokenErr(t *testing.T) {
+	data := []struct {
+		name           string
+		token          Token
+		expected       []TokenType
+		expectedErrMsg string
+	}{
+		{"single type", Token{Type: CommaToken}, []TokenType{StateToken}, "expected state name, got ','"},
+		{"two types", Token{Type: EOFToken}, []TokenType{CommaToken, RightParenToken}, "expected ',' or ')', got end of source"},
+		{
+			"many types",
+			Token{Type: ClassEndToken},
+			[]TokenType{StateToken, SymbolToken, SemicolonToken},
+			"expected state name, symbol or ';', got '}' ending symbol class",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			errMsg := UnexpectedTokenErr(d.token, d.expected...).Error()
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+		})
+	}
+}
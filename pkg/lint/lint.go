@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"automata-compiler/pkg/automaton"
+	"automata-compiler/pkg/compiler"
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Warning describes a problem that doesn't prevent automaton from running, but most likely is a mistake
+type Warning struct {
+	Line    int
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("[Line %d] %s", w.Line, w.Message)
+}
+
+// transition is a transition function entry reduced to what is needed by the checks
+type transition struct {
+	from string
+	to   string
+	line int
+}
+
+// automatonInfo holds the parts of the automaton common for all automaton types
+type automatonInfo struct {
+	states       map[string]automaton.State
+	initialState string
+	// transitions are unique, so transitions matching many symbols (e.g. with `*` or `{a, b}`) are checked once
+	transitions map[transition]bool
+	// usedSymbols are symbols read or written by any transition
+	usedSymbols map[string]bool
+	// finalStatesStop is set if calculations stop as soon as automaton enters accepting state
+	finalStatesStop bool
+}
+
+// Lint checks compiled automaton for unreachable states, states from which no accepting state can be reached,
+// unused symbols and transitions that can never be used. `lines` are used to find lines of reported elements.
+// Warnings are sorted by line.
+func Lint(a automaton.Automaton, lines compiler.SourceLines) ([]Warning, error) {
+	info, err := newAutomatonInfo(a, lines)
+	if err != nil {
+		return nil, err
+	}
+	warnings := make([]Warning, 0)
+	warnings = append(warnings, checkUnreachableStates(info, lines)...)
+	warnings = append(warnings, checkDeadStates(info, lines)...)
+	warnings = append(warnings, checkUnusedSymbols(info, lines)...)
+	warnings = append(warnings, checkTransitionsFromFinalStates(info)...)
+	slices.SortStableFunc(warnings, func(a, b Warning) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Message, b.Message))
+	})
+	return warnings, nil
+}
+
+func newAutomatonInfo(a automaton.Automaton, lines compiler.SourceLines) (automatonInfo, error) {
+	info := automatonInfo{transitions: make(map[transition]bool), usedSymbols: make(map[string]bool)}
+	switch v := a.(type) {
+	case *automaton.DeterministicFiniteAutomaton:
+		info.states, info.initialState = v.States, v.CurrentState
+		for key, val := range v.Transitions {
+			info.transitions[transition{from: key.StateName, to: val.StateName, line: lines.Transitions[key]}] = true
+			info.usedSymbols[key.SymbolName] = true
+		}
+	case *automaton.PushdownAutomaton:
+		info.states, info.initialState = v.States, v.CurrentState
+		for key, val := range v.Transitions {
+			info.transitions[transition{from: key.StateName, to: val.StateName, line: lines.Transitions[key]}] = true
+			info.usedSymbols[key.InputSymbolName] = true
+			info.usedSymbols[key.StackSymbolName] = true
+			for _, s := range val.StackSymbolNames {
+				info.usedSymbols[s] = true
+			}
+		}
+	case *automaton.TuringMachine:
+		info.states, info.initialState = v.States, v.CurrentState
+		info.finalStatesStop = true
+		for key, val := range v.Transitions {
+			info.transitions[transition{from: key.StateName, to: val.StateName, line: lines.Transitions[key]}] = true
+			info.usedSymbols[key.SymbolName] = true
+			info.usedSymbols[val.SymbolName] = true
+		}
+	default:
+		return info, fmt.Errorf("unsupported automaton type: %T", a)
+	}
+	return info, nil
+}
+
+// reachable returns states reachable from `start` following `next`
+func reachable(start []string, next map[string][]string) map[string]bool {
+	visited := make(map[string]bool)
+	queue := slices.Clone(start)
+	for _, s := range start {
+		visited[s] = true
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, n := range next[s] {
+			if !visited[n] {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return visited
+}
+
+func checkUnreachableStates(info automatonInfo, lines compiler.SourceLines) []Warning {
+	next := make(map[string][]string)
+	for t := range info.transitions {
+		// Transitions from accepting states are never used if calculations stop there
+		if info.finalStatesStop && info.states[t.from].Accepting {
+			continue
+		}
+		next[t.from] = append(next[t.from], t.to)
+	}
+	visited := reachable([]string{info.initialState}, next)
+	warnings := make([]Warning, 0)
+	for name := range info.states {
+		if !visited[name] {
+			warnings = append(warnings, Warning{
+				Line:    lines.States[name],
+				Message: fmt.Sprintf("state %s is unreachable from the initial state %s", name, info.initialState),
+			})
+		}
+	}
+	return warnings
+}
+
+func checkDeadStates(info automatonInfo, lines compiler.SourceLines) []Warning {
+	previous := make(map[string][]string)
+	for t := range info.transitions {
+		previous[t.to] = append(previous[t.to], t.from)
+	}
+	accepting := make([]string, 0)
+	for name, s := range info.states {
+		if s.Accepting {
+			accepting = append(accepting, name)
+		}
+	}
+	visited := reachable(accepting, previous)
+	warnings := make([]Warning, 0)
+	for name := range info.states {
+		if !visited[name] {
+			warnings = append(warnings, Warning{
+				Line:    lines.States[name],
+				Message: fmt.Sprintf("no accepting state is reachable from state %s", name),
+			})
+		}
+	}
+	return warnings
+}
+
+func checkUnusedSymbols(info automatonInfo, lines compiler.SourceLines) []Warning {
+	warnings := make([]Warning, 0)
+	// Only symbols declared in the source are checked, special symbols are added by the compiler
+	for name, line := range lines.Symbols {
+		if !info.usedSymbols[name] {
+			warnings = append(warnings, Warning{
+				Line:    line,
+				Message: fmt.Sprintf("symbol %s is declared but never used in transitions", name),
+			})
+		}
+	}
+	return warnings
+}
+
+func checkTransitionsFromFinalStates(info automatonInfo) []Warning {
+	warnings := make([]Warning, 0)
+	if !info.finalStatesStop {
+		return warnings
+	}
+	for t := range info.transitions {
+		if info.states[t.from].Accepting {
+			warnings = append(warnings, Warning{
+				Line:    t.line,
+				Message: fmt.Sprintf("transition from accepting state %s is never used, calculations stop in accepting states", t.from),
+			})
+		}
+	}
+	return warnings
+}
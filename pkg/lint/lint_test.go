@@ -0,0 +1,128 @@
+package lint
+
+import (
+	"automata-compiler/pkg/compiler"
+	"automata-compiler/pkg/lexer"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	data := []struct {
+		name        string
+		newCompiler func(tokens []lexer.Token) compiler.Compiler
+		source      string
+		expected    []Warning
+	}{
+		{
+			"DFA without warnings",
+			func(tokens []lexer.Token) compiler.Compiler {
+				return compiler.NewDeterministicFiniteAutomatonCompiler(tokens)
+			},
+			`qA qB;
+qA;
+qB;
+0 1;
+(qA, 0) > (qB)
+(qB, 1) > (qA);
+0;`,
+			[]Warning{},
+		},
+		{
+			"DFA",
+			func(tokens []lexer.Token) compiler.Compiler {
+				return compiler.NewDeterministicFiniteAutomatonCompiler(tokens)
+			},
+			`qA qB
+qC
+qD;
+qA;
+qB;
+0 1
+2;
+(qA, 0) > (qB)
+(qA, 1) > (qC)
+(qD, 0) > (qB);
+0;`,
+			[]Warning{
+				{Line: 2, Message: "no accepting state is reachable from state qC"},
+				{Line: 3, Message: "state qD is unreachable from the initial state qA"},
+				{Line: 7, Message: "symbol 2 is declared but never used in transitions"},
+			},
+		},
+		{
+			"PA",
+			func(tokens []lexer.Token) compiler.Compiler { return compiler.NewPushdownAutomatonCompiler(tokens) },
+			`qA qB
+qC;
+qA;
+qB;
+0 X
+Y;
+(qA, 0, }) > (qA, }, X)
+(qA, {, X) > (qB)
+(qC, {, }) > (qC, });
+0;`,
+			[]Warning{
+				{Line: 2, Message: "no accepting state is reachable from state qC"},
+				{Line: 2, Message: "state qC is unreachable from the initial state qA"},
+				{Line: 6, Message: "symbol Y is declared but never used in transitions"},
+			},
+		},
+		{
+			"TM",
+			func(tokens []lexer.Token) compiler.Compiler { return compiler.NewTuringMachineCompiler(tokens) },
+			`qA qB
+qC;
+qA;
+qB;
+1;
+(qA, 1) > (qB, 1, R)
+(qB, B) > (qC, B, R)
+(qC, B) > (qB, B, L);
+1;`,
+			[]Warning{
+				{Line: 2, Message: "state qC is unreachable from the initial state qA"},
+				{Line: 7, Message: "transition from accepting state qB is never used, calculations stop in accepting states"},
+			},
+		},
+		{
+			"TM with wildcards and classes",
+			func(tokens []lexer.Token) compiler.Compiler { return compiler.NewTuringMachineCompiler(tokens) },
+			`qA qB qC;
+qA;
+qB qC;
+a b;
+(qA, a) > (qB, a, R)
+(qA, b) > (qC, b, R)
+(qB, *) > (qB, *, R)
+(qC, {a, b}) > (qA, a, L);
+a;`,
+			[]Warning{
+				{Line: 7, Message: "transition from accepting state qB is never used, calculations stop in accepting states"},
+				{Line: 8, Message: "transition from accepting state qC is never used, calculations stop in accepting states"},
+			},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			tokens, err := lexer.NewLexer(d.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := d.newCompiler(tokens)
+			a, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Lint(a, c.SourceLines())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.expected, result); diff != "" {
+				t.Errorf("invalid warnings (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
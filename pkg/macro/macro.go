@@ -0,0 +1,299 @@
+package macro
+
+import (
+	"automata-compiler/pkg/compiler"
+	"automata-compiler/pkg/lexer"
+	"fmt"
+	"slices"
+)
+
+// Expander reads tokens from `reader` and expands macros defined at the start of the source, after the optional
+// header. Macro is defined as follows:
+//
+//	macro name(param1, param2, ...) body;
+//
+// where parameters are state names or symbols and the body is a group of transitions. Macro is used in
+// the transitions section as `name(arg1, arg2, ...)`, which is replaced by the body with parameters replaced
+// by the arguments. States used in the body that are not parameters are local to every use of the macro: they
+// get unique names (e.g. `name_1_qLoop`) that can't be used in the source, and they are added to the states section.
+type Expander struct {
+	reader lexer.TokenReader
+	macros map[string]macro
+	// uses counts uses of every macro, to give local states unique names
+	uses map[string]int
+	// tokens are read or expanded tokens waiting to be returned by `Next`
+	tokens []lexer.Token
+	// started is set when the definitions at the start of the source are processed, if any macro is defined
+	// the whole source is expanded at once, otherwise the rest of the source is read from `reader` as needed
+	started bool
+	err     error
+}
+
+type macro struct {
+	name   lexer.Token
+	params []lexer.Token
+	body   []lexer.Token
+}
+
+// symbolArgTypes are types of tokens that can be passed as symbol arguments
+var symbolArgTypes = []lexer.TokenType{
+	lexer.SymbolToken,
+	lexer.BlankSymbolToken,
+	lexer.MoveLeftToken,
+	lexer.MoveRightToken,
+	lexer.InputEndToken,
+	lexer.StackStartToken,
+	lexer.WildcardToken,
+}
+
+func NewExpander(r lexer.TokenReader) *Expander {
+	return &Expander{
+		reader: r,
+		macros: make(map[string]macro),
+		uses:   make(map[string]int),
+		tokens: make([]lexer.Token, 0),
+	}
+}
+
+// Next returns the next token of the source with macros expanded
+func (e *Expander) Next() (lexer.Token, error) {
+	if !e.started {
+		e.started = true
+		e.err = e.start()
+	}
+	if e.err != nil {
+		return lexer.Token{}, e.err
+	}
+	if len(e.tokens) == 0 {
+		return e.reader.Next()
+	}
+	t := e.tokens[0]
+	// EOF is returned again by every following call
+	if t.Type != lexer.EOFToken || len(e.tokens) > 1 {
+		e.tokens = e.tokens[1:]
+	}
+	return t, nil
+}
+
+// start processes the header and macro definitions, if any macro is defined it expands the rest of the source
+func (e *Expander) start() error {
+	t, err := e.reader.Next()
+	if err != nil {
+		return err
+	}
+	if t.Type == lexer.TypeToken {
+		e.tokens = append(e.tokens, t)
+		for t.Type != lexer.SemicolonToken && t.Type != lexer.EOFToken {
+			if t, err = e.reader.Next(); err != nil {
+				return err
+			}
+			e.tokens = append(e.tokens, t)
+		}
+		if t, err = e.reader.Next(); err != nil {
+			return err
+		}
+	}
+	for t.Type == lexer.MacroToken {
+		if err := e.define(); err != nil {
+			return err
+		}
+		if t, err = e.reader.Next(); err != nil {
+			return err
+		}
+	}
+	e.tokens = append(e.tokens, t)
+	if len(e.macros) == 0 {
+		return nil
+	}
+	source := e.tokens[len(e.tokens)-1:]
+	for t.Type != lexer.EOFToken {
+		if t, err = e.reader.Next(); err != nil {
+			return err
+		}
+		source = append(source, t)
+	}
+	expanded, locals, err := e.expand(source)
+	if err != nil {
+		return err
+	}
+	e.tokens = append(e.tokens[:len(e.tokens)-1], declareStates(expanded, locals)...)
+	return nil
+}
+
+// define processes macro definition, `macro` keyword is already consumed
+func (e *Expander) define() error {
+	name, err := e.consume(lexer.SymbolToken)
+	if err != nil {
+		return err
+	}
+	if prev, ok := e.macros[name.Value]; ok {
+		return newError(name, "macro %s already defined in line %d", name.Value, prev.name.Line)
+	}
+	if _, err := e.consume(lexer.LeftParenToken); err != nil {
+		return err
+	}
+	m := macro{name: name, params: make([]lexer.Token, 0), body: make([]lexer.Token, 0)}
+	t, err := e.consume(lexer.StateToken, lexer.SymbolToken, lexer.RightParenToken)
+	for err == nil && t.Type != lexer.RightParenToken {
+		if slices.ContainsFunc(m.params, func(p lexer.Token) bool { return p.Value == t.Value }) {
+			return newError(t, "parameter %s of macro %s declared more than once", t.Value, name.Value)
+		}
+		m.params = append(m.params, t)
+		if t, err = e.consume(lexer.CommaToken, lexer.RightParenToken); err == nil && t.Type == lexer.CommaToken {
+			t, err = e.consume(lexer.StateToken, lexer.SymbolToken)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for {
+		t, err := e.reader.Next()
+		if err != nil {
+			return err
+		}
+		switch t.Type {
+		case lexer.SemicolonToken:
+			e.macros[name.Value] = m
+			return nil
+		case lexer.EOFToken, lexer.MacroToken:
+			return newError(t, "missing ';' at the end of macro %s", name.Value)
+		case lexer.SymbolToken:
+			// Macros defined later can't be used, so they can't form a cycle
+			if t.Value == name.Value {
+				return newError(t, "macro %s can't use itself", name.Value)
+			}
+		}
+		m.body = append(m.body, t)
+	}
+}
+
+// consume reads the next token and returns an error if it's not one of the `expected` types
+func (e *Expander) consume(expected ...lexer.TokenType) (lexer.Token, error) {
+	t, err := e.reader.Next()
+	if err != nil {
+		return t, err
+	}
+	if !slices.Contains(expected, t.Type) {
+		return t, errorAt(t, lexer.UnexpectedTokenErr(t, expected...))
+	}
+	return t, nil
+}
+
+// expand returns `tokens` with macros replaced by their bodies and local states of all the uses
+func (e *Expander) expand(tokens []lexer.Token) ([]lexer.Token, []lexer.Token, error) {
+	expanded := make([]lexer.Token, 0, len(tokens))
+	locals := make([]lexer.Token, 0)
+	for i := 0; i < len(tokens); i++ {
+		m, ok := e.macros[tokens[i].Value]
+		if tokens[i].Type != lexer.SymbolToken || !ok || i+1 >= len(tokens) || tokens[i+1].Type != lexer.LeftParenToken {
+			expanded = append(expanded, tokens[i])
+			continue
+		}
+		args, end, err := arguments(m, tokens, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, bodyLocals := e.substitute(m, tokens[i], args)
+		// Body can use macros defined earlier
+		body, nestedLocals, err := e.expand(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		expanded = append(expanded, body...)
+		locals = append(append(locals, bodyLocals...), nestedLocals...)
+		i = end
+	}
+	return expanded, locals, nil
+}
+
+// arguments returns arguments of use of macro `m` starting with the name at index `start` of `tokens`, and index
+// of ')' ending the use
+func arguments(m macro, tokens []lexer.Token, start int) ([]lexer.Token, int, error) {
+	args := make([]lexer.Token, 0, len(m.params))
+	// Skip name and '('
+	i := start + 2
+	for ; i < len(tokens) && tokens[i].Type != lexer.RightParenToken; i++ {
+		if len(args) > 0 {
+			if tokens[i].Type != lexer.CommaToken {
+				return nil, 0, errorAt(tokens[i], lexer.UnexpectedTokenErr(tokens[i], lexer.CommaToken, lexer.RightParenToken))
+			}
+			if i++; i >= len(tokens) {
+				break
+			}
+		}
+		if len(args) == len(m.params) {
+			return nil, 0, newError(tokens[i], "too many arguments of macro %s, expected %d", m.name.Value, len(m.params))
+		}
+		t, param := tokens[i], m.params[len(args)]
+		if param.Type == lexer.StateToken && t.Type != lexer.StateToken {
+			return nil, 0, newError(t, "argument %s of macro %s must be a state name, got %s", param.Value, m.name.Value, t.Type.Description())
+		}
+		if param.Type == lexer.SymbolToken && !slices.Contains(symbolArgTypes, t.Type) {
+			return nil, 0, newError(t, "argument %s of macro %s must be a symbol, got %s", param.Value, m.name.Value, t.Type.Description())
+		}
+		args = append(args, t)
+	}
+	if i >= len(tokens) {
+		return nil, 0, newError(tokens[start], "unfinished use of macro %s", m.name.Value)
+	}
+	if len(args) < len(m.params) {
+		return nil, 0, newError(tokens[i], "too few arguments of macro %s, expected %d", m.name.Value, len(m.params))
+	}
+	return args, i, nil
+}
+
+// substitute returns body of macro `m` used at token `use` with parameters replaced by `args` and local states
+// renamed, local states are returned as well
+func (e *Expander) substitute(m macro, use lexer.Token, args []lexer.Token) ([]lexer.Token, []lexer.Token) {
+	e.uses[m.name.Value]++
+	body := make([]lexer.Token, 0, len(m.body))
+	locals := make([]lexer.Token, 0)
+	for _, t := range m.body {
+		i := slices.IndexFunc(m.params, func(p lexer.Token) bool { return p.Type == t.Type && p.Value == t.Value })
+		if i >= 0 {
+			body = append(body, args[i])
+			continue
+		}
+		if t.Type == lexer.StateToken {
+			// Names of states can't contain '_', so local states never clash with states of the source
+			t.Value = fmt.Sprintf("%s_%d_%s", m.name.Value, e.uses[m.name.Value], t.Value)
+			if !slices.ContainsFunc(locals, func(l lexer.Token) bool { return l.Value == t.Value }) {
+				local := use
+				local.Type, local.Value = lexer.StateToken, t.Value
+				locals = append(locals, local)
+			}
+		}
+		body = append(body, t)
+	}
+	return body, locals
+}
+
+// declareStates adds `states` at the end of the states section of `tokens`, which is either the first section
+// or the section named `states:`
+func declareStates(tokens []lexer.Token, states []lexer.Token) []lexer.Token {
+	start := 0
+	if len(tokens) > 0 && tokens[0].Type == lexer.SectionToken {
+		start = slices.IndexFunc(tokens, func(t lexer.Token) bool { return t.Type == lexer.SectionToken && t.Value == "states" })
+		if start < 0 {
+			// Missing section is reported by the compiler
+			return tokens
+		}
+	}
+	end := slices.IndexFunc(tokens[start:], func(t lexer.Token) bool {
+		return t.Type == lexer.SemicolonToken || t.Type == lexer.EOFToken
+	})
+	if end < 0 {
+		return tokens
+	}
+	return slices.Insert(tokens, start+end, states...)
+}
+
+// newError creates error found at token `t`, it's reported by the compiler like any other compilation error
+func newError(t lexer.Token, format string, args ...any) error {
+	return errorAt(t, fmt.Errorf(format, args...))
+}
+
+// errorAt adds position of token `t` to `err`
+func errorAt(t lexer.Token, err error) error {
+	return compiler.CompileError{Line: t.Line, Column: t.Column, Span: t.Span, Err: err}
+}
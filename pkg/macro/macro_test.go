@@ -0,0 +1,193 @@
+package macro
+
+import (
+	"automata-compiler/pkg/lexer"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const scanRight = "macro scanRight(@from, @to, stop)\n" +
+	"(@from, *) > (qLoop, *, R)\n" +
+	"(qLoop, {0, 1}) > (qLoop, *, R)\n" +
+	"(qLoop, stop) > (@to, stop, L);\n"
+
+func TestExpander(t *testing.T) {
+	data := []struct {
+		name   string
+		source string
+		// expected are values of tokens of the source with macros expanded, empty if expanding fails
+		expected       string
+		expectedErrMsg string
+	}{
+		{
+			"no macros",
+			"type TM;\nqA;\nqA;\nqA;\n0;\n(qA, 0) > (qA, 0, R);\n",
+			"type TM ; qA ; qA ; qA ; 0 ; ( qA , 0 ) > ( qA , 0 , R ) ;",
+			"",
+		},
+		{
+			"macro used twice",
+			"type TM;\n" + scanRight + "qA qB qC;\nqA;\nqC;\n0 1;\nscanRight(@qA, @qB, B)\nscanRight(@qB, @qC, 0);\n",
+			"type TM ; qA qB qC scanRight_1_qLoop scanRight_2_qLoop ; qA ; qC ; 0 1 ; " +
+				"( qA , * ) > ( scanRight_1_qLoop , * , R ) " +
+				"( scanRight_1_qLoop , { 0 , 1 } ) > ( scanRight_1_qLoop , * , R ) " +
+				"( scanRight_1_qLoop , B ) > ( qB , B , L ) " +
+				"( qB , * ) > ( scanRight_2_qLoop , * , R ) " +
+				"( scanRight_2_qLoop , { 0 , 1 } ) > ( scanRight_2_qLoop , * , R ) " +
+				"( scanRight_2_qLoop , 0 ) > ( qC , 0 , L ) ;",
+			"",
+		},
+		{
+			"local states in named states section",
+			scanRight + "symbols: 0 1;\nstates: qA qB;\ntransitions: scanRight(@qA, @qB, B);\n",
+			"symbols 0 1 ; states qA qB scanRight_1_qLoop ; transitions " +
+				"( qA , * ) > ( scanRight_1_qLoop , * , R ) " +
+				"( scanRight_1_qLoop , { 0 , 1 } ) > ( scanRight_1_qLoop , * , R ) " +
+				"( scanRight_1_qLoop , B ) > ( qB , B , L ) ;",
+			"",
+		},
+		{
+			"macro used by another macro",
+			"macro step(@from, @to, x) (@from, x) > (@to);\n" +
+				"macro twice(@from, @to, x) step(@from, @mid, x) step(@mid, @to, x);\n" +
+				"qA qB;\nqA;\nqB;\n0;\ntwice(@qA, @qB, 0);\n",
+			"qA qB twice_1_mid ; qA ; qB ; 0 ; ( qA , 0 ) > ( twice_1_mid ) ( twice_1_mid , 0 ) > ( qB ) ;",
+			"",
+		},
+		{
+			"macro name used as symbol",
+			"macro f(@s, x) (@s, x) > (@s);\nqA;\nqA;\nqA;\nf;\nf(@qA, f);\n",
+			"qA ; qA ; qA ; f ; ( qA , f ) > ( qA ) ;",
+			"",
+		},
+		{
+			"macro defined twice",
+			"macro f(x) (qA, x) > (qA);\nmacro f(y) (qA, y) > (qA);\n",
+			"",
+			"[Line 2, Column 7] macro f already defined in line 1",
+		},
+		{
+			"parameter declared twice",
+			"macro f(x, @qA, x) (qA, x) > (qA);\n",
+			"",
+			"[Line 1, Column 17] parameter x of macro f declared more than once",
+		},
+		{
+			"macro using itself",
+			"macro f(x) (qA, x) > (qA) f(x);\n",
+			"",
+			"[Line 1, Column 27] macro f can't use itself",
+		},
+		{
+			"missing semicolon after macro",
+			"macro f(x) (qA, x) > (qA)\nmacro g(x) (qA, x) > (qA);\n",
+			"",
+			"[Line 2, Column 1] missing ';' at the end of macro f",
+		},
+		{
+			"invalid parameter",
+			"macro f(x, >) (qA, x) > (qA);\n",
+			"",
+			"[Line 1, Column 12] expected state name or symbol, got '>'",
+		},
+		{
+			"state argument for symbol parameter",
+			"macro f(x) (qA, x) > (qA);\nqA;\nqA;\nqA;\n0;\nf(@qA);\n",
+			"",
+			"[Line 6, Column 3] argument x of macro f must be a symbol, got state name",
+		},
+		{
+			"too many arguments",
+			"macro f(x) (qA, x) > (qA);\nqA;\nqA;\nqA;\n0;\nf(0, 0);\n",
+			"",
+			"[Line 6, Column 6] too many arguments of macro f, expected 1",
+		},
+		{
+			"too few arguments",
+			"macro f(x, y) (qA, x) > (qA, y, R);\nqA;\nqA;\nqA;\n0;\nf(0);\n",
+			"",
+			"[Line 6, Column 4] too few arguments of macro f, expected 2",
+		},
+		{
+			"unfinished use",
+			"macro f(x) (qA, x) > (qA);\nqA;\nqA;\nqA;\n0;\nf(0",
+			"",
+			"[Line 6, Column 4] expected ',' or ')', got end of source",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			result, err := readAll(NewExpander(lexer.NewLexer(d.source)))
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.expectedErrMsg {
+				t.Errorf("invalid error message, expected: %s, got: %s", d.expectedErrMsg, errMsg)
+			}
+			if d.expected == "" {
+				return
+			}
+			// Local states can't be lexed, so values of tokens are compared instead of tokens of the expected source
+			values := make([]string, 0, len(result))
+			for _, t := range result[:len(result)-1] {
+				values = append(values, t.Value)
+			}
+			if diff := cmp.Diff(d.expected, strings.Join(values, " ")); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// readAll returns all tokens read from `r` up to EOF
+func readAll(r lexer.TokenReader) ([]lexer.Token, error) {
+	tokens := make([]lexer.Token, 0)
+	for {
+		t, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.Type == lexer.EOFToken {
+			return tokens, nil
+		}
+	}
+}
+
+// countingReader counts tokens read from `r`
+type countingReader struct {
+	r     lexer.TokenReader
+	count int
+}
+
+func (c *countingReader) Next() (lexer.Token, error) {
+	c.count++
+	return c.r.Next()
+}
+
+func TestExpanderReadsOnlyDefinitionsAhead(t *testing.T) {
+	data := []struct {
+		name   string
+		source string
+		// expectedCount is the number of tokens read from the source to return the first token
+		expectedCount int
+	}{
+		{"no macros", "qA qB;\nqA;\nqB;\n", 1},
+		{"header without macros", "type TM;\nqA qB;\nqA;\nqB;\n", 4},
+		{"macros", "macro f(x) (qA, x) > (qA);\nqA qB;\nqA;\nqB;\n", 23},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			r := &countingReader{r: lexer.NewLexer(d.source)}
+			if _, err := NewExpander(r).Next(); err != nil {
+				t.Fatal(err)
+			}
+			if r.count != d.expectedCount {
+				t.Errorf("invalid number of tokens read, expected: %d, got: %d", d.expectedCount, r.count)
+			}
+		})
+	}
+}